@@ -0,0 +1,199 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// condition makes a field required depending on another field of the same
+// struct, set by a `required_if:"Field=value"` or `required_with:"Field"` tag.
+type condition struct {
+	// With is set for required_with conditions, met when the field is set.
+	// required_if conditions are met when the field has Value.
+	With  bool
+	Field int
+	Key   string
+	Value string
+}
+
+// fieldStatus tells how a field was processed
+type fieldStatus int
+
+const (
+	statusUnset fieldStatus = iota
+	statusDefault
+	statusSet
+)
+
+// resolveConditions resolves the field names of the required_if and
+// required_with tags among the fields of the same struct, and the exclusive
+// groups fields belong to.
+func resolveConditions(infos []varInfo) error {
+	for i := range infos {
+		info := &infos[i]
+		if tag := info.Tags.Get("required_if"); tag != "" {
+			kv := strings.SplitN(tag, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("envconfig: %s: invalid required_if tag %q, expecting Field=value", info.Path, tag)
+			}
+			ref, err := findSibling(infos, info.Path, kv[0], "required_if")
+			if err != nil {
+				return err
+			}
+			info.Conditions = append(info.Conditions, condition{Field: ref, Key: infos[ref].Key, Value: kv[1]})
+		}
+		if tag := info.Tags.Get("required_with"); tag != "" {
+			for _, name := range strings.Split(tag, ",") {
+				ref, err := findSibling(infos, info.Path, strings.TrimSpace(name), "required_with")
+				if err != nil {
+					return err
+				}
+				info.Conditions = append(info.Conditions, condition{With: true, Field: ref, Key: infos[ref].Key})
+			}
+		}
+	}
+	for _, g := range exclusiveGroups(infos) {
+		keys := g.keys(infos)
+		for _, i := range g.fields {
+			infos[i].Exclusive = keys
+		}
+	}
+	return nil
+}
+
+// parentPath returns the path of the struct holding a field
+func parentPath(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// findSibling returns the index of the field called name, in the same struct
+// as the field at path
+func findSibling(infos []varInfo, path, name, tag string) (int, error) {
+	siblingPath := name
+	if parent := parentPath(path); parent != "" {
+		siblingPath = parent + "." + name
+	}
+	for i, info := range infos {
+		if info.Path == siblingPath {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("envconfig: %s: %s refers to unknown field %s", path, tag, name)
+}
+
+// String renders the condition for error messages and usage output
+func (c condition) String() string {
+	if c.With {
+		return "with " + c.Key
+	}
+	return "if " + c.Key + "=" + c.Value
+}
+
+// checkConditions reports the fields required by a condition that is met,
+// and the exclusive groups that do not have exactly one field set.
+func checkConditions(infos []varInfo, statuses []fieldStatus) Errors {
+	var errs Errors
+	for i, info := range infos {
+		if statuses[i] != statusUnset {
+			continue
+		}
+		for _, c := range info.Conditions {
+			if !c.met(infos, statuses) {
+				continue
+			}
+			errs = append(errs, &RequiredError{
+				Key:          info.Key,
+				FieldName:    info.Name,
+				Path:         info.Path,
+				Alternatives: info.alternatives(),
+				Condition:    c.String(),
+			})
+			break
+		}
+	}
+
+	for _, g := range exclusiveGroups(infos) {
+		var set []string
+		for _, i := range g.fields {
+			if statuses[i] == statusSet {
+				set = append(set, infos[i].Key)
+			}
+		}
+		if len(set) != 1 {
+			errs = append(errs, &GroupError{Group: g.name, Keys: g.keys(infos), Set: set})
+		}
+	}
+	return errs
+}
+
+func (c condition) met(infos []varInfo, statuses []fieldStatus) bool {
+	if c.With {
+		return statuses[c.Field] == statusSet
+	}
+	return statuses[c.Field] != statusUnset && renderValue(infos[c.Field].Field, infos[c.Field].separators()) == c.Value
+}
+
+type group struct {
+	name   string
+	fields []int
+}
+
+func (g group) keys(infos []varInfo) []string {
+	keys := make([]string, len(g.fields))
+	for i, f := range g.fields {
+		keys[i] = infos[f].Key
+	}
+	return keys
+}
+
+// exclusiveGroups lists the groups of fields sharing a group tag in a struct,
+// one of them at least being tagged with `exclusive:"true"`
+func exclusiveGroups(infos []varInfo) []group {
+	groups := map[string]*group{}
+	exclusive := map[string]bool{}
+	var ids []string
+	for i, info := range infos {
+		name := info.Tags.Get("group")
+		if name == "" {
+			continue
+		}
+		id := parentPath(info.Path) + ":" + name
+		g, found := groups[id]
+		if !found {
+			g = &group{name: name}
+			groups[id] = g
+			ids = append(ids, id)
+		}
+		g.fields = append(g.fields, i)
+		if isTrue(info.Tags.Get("exclusive")) {
+			exclusive[id] = true
+		}
+	}
+
+	var list []group
+	for _, id := range ids {
+		if exclusive[id] {
+			list = append(list, *groups[id])
+		}
+	}
+	return list
+}
+
+// requirement describes when a field is required, for usage output
+func (v varInfo) requirement() string {
+	var r []string
+	for _, c := range v.Conditions {
+		r = append(r, c.String())
+	}
+	if len(v.Exclusive) > 0 {
+		r = append(r, "one of "+strings.Join(v.Exclusive, ", "))
+	}
+	return strings.Join(r, ", ")
+}
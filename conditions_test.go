@@ -0,0 +1,95 @@
+package envconfig
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type authSpec struct {
+	AuthMode   string `default:"none" split_words:"true"`
+	OIDCIssuer string `required_if:"AuthMode=oidc" envconfig:"oidc_issuer"`
+	TLSCert    string `required_with:"TLSKey" envconfig:"tls_cert"`
+	TLSKey     string `envconfig:"tls_key"`
+	DB         struct {
+		DSN  string `group:"db" exclusive:"true"`
+		Host string `group:"db"`
+	}
+}
+
+func TestConditionalRequirements(t *testing.T) {
+	t.Parallel()
+	var s authSpec
+	l := MapLookuper{"APP_DB_DSN": "postgres://"}
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+
+	l = MapLookuper{
+		"APP_AUTH_MODE": "oidc",
+		"APP_TLS_KEY":   "key.pem",
+		"APP_DB_DSN":    "postgres://",
+		"APP_DB_HOST":   "localhost",
+	}
+	err := ProcessWith(l, "app", &s)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %T %v", err, err)
+	}
+	expected := []string{
+		"key APP_OIDC_ISSUER required if APP_AUTH_MODE=oidc missing value (also tried OIDC_ISSUER)",
+		"key APP_TLS_CERT required with APP_TLS_KEY missing value (also tried TLS_CERT)",
+		"exactly one of APP_DB_DSN, APP_DB_HOST must be set for group db, got APP_DB_DSN, APP_DB_HOST",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], err)
+		}
+	}
+	if v, ok := errs[0].(*RequiredError); !ok || v.Condition != "if APP_AUTH_MODE=oidc" {
+		t.Errorf("expected RequiredError with condition, got %#v", errs[0])
+	}
+	if v, ok := errs[2].(*GroupError); !ok || !reflect.DeepEqual(v.Set, []string{"APP_DB_DSN", "APP_DB_HOST"}) {
+		t.Errorf("expected GroupError, got %#v", errs[2])
+	}
+
+	err = ProcessWith(MapLookuper{}, "app", &s)
+	if experr := "exactly one of APP_DB_DSN, APP_DB_HOST must be set for group db, got none"; err == nil || err.Error() != experr {
+		t.Errorf("expected %s, got %v", experr, err)
+	}
+}
+
+func TestConditionUnknownField(t *testing.T) {
+	t.Parallel()
+	var s struct {
+		Issuer string `required_if:"Mode=oidc"`
+	}
+	err := ProcessWith(MapLookuper{}, "app", &s)
+	if experr := "envconfig: Issuer: required_if refers to unknown field Mode"; err == nil || err.Error() != experr {
+		t.Errorf("expected %s, got %v", experr, err)
+	}
+}
+
+func TestUsageConditions(t *testing.T) {
+	t.Parallel()
+	var s authSpec
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{usage_key .}}={{usage_required .}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"APP_OIDC_ISSUER=if APP_AUTH_MODE=oidc",
+		"APP_TLS_CERT=with APP_TLS_KEY",
+		"APP_DB_DSN=one of APP_DB_DSN, APP_DB_HOST",
+		"APP_DB_HOST=one of APP_DB_DSN, APP_DB_HOST",
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected %q in %q", line, buf)
+		}
+	}
+}
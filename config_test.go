@@ -1,7 +1,6 @@
 package envconfig
 
 import (
-	"os"
 	"testing"
 	"time"
 
@@ -61,19 +60,6 @@ type Config6 struct {
 
 type Env map[string]string
 
-func (e Env) setEnv() {
-	for k, v := range e {
-		os.Setenv(k, v)
-	}
-}
-
-func (e Env) clearEnv() {
-	/*for k := range e {
-		os.Setenv(k, "")
-	}*/
-	os.Clearenv()
-}
-
 var expected = &Config{
 	HTTPConfig: HTTPConfig{
 		HTTPPort:           8088,
@@ -393,11 +379,9 @@ func TestConfigs(t *testing.T) {
 	}
 	for i, test := range tests {
 		t.Log("test envconfig with ", i, test)
-		test.env.setEnv()
-		if err := Process(test.prefix, test.cfg); err != nil {
+		if err := ProcessWith(MapLookuper(test.env), test.prefix, test.cfg); err != nil {
 			t.Error(err)
 		}
 		assert.Equal(t, test.expected, test.cfg)
-		test.env.clearEnv()
 	}
 }
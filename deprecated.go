@@ -0,0 +1,64 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import "log"
+
+// A DeprecationWarning reports a value found under a deprecated name, that is
+// an alias of a field tagged with `deprecated:"message"`, or any name of such
+// a field when it has no alias.
+type DeprecationWarning struct {
+	// Key is the deprecated name the value was found under.
+	Key string
+	// Replacement is the primary key of the field, when Key is an alias.
+	Replacement string
+	FieldName   string
+	Path        string
+	// Message is the value of the deprecated tag.
+	Message string
+}
+
+func (w *DeprecationWarning) String() string {
+	s := "envconfig: " + w.Key + " is deprecated"
+	if w.Replacement != "" {
+		s += " in favor of " + w.Replacement
+	}
+	if w.Message != "" {
+		s += ": " + w.Message
+	}
+	return s
+}
+
+// logDeprecation is the default deprecation handler
+func logDeprecation(w *DeprecationWarning) {
+	log.Print(w)
+}
+
+// deprecation returns the warning for a value of the field found under key,
+// or nil if key is not deprecated
+func (v varInfo) deprecation(key string) *DeprecationWarning {
+	msg, ok := v.Tags.Lookup("deprecated")
+	if !ok {
+		return nil
+	}
+	w := &DeprecationWarning{Key: key, FieldName: v.Name, Path: v.Path, Message: msg}
+	if len(v.Aliases) > 0 {
+		if !v.isAlias(key) {
+			return nil
+		}
+		w.Replacement = v.Key
+	}
+	return w
+}
+
+// isAlias tells whether key is an alias of the field, or its _FILE variant
+func (v varInfo) isAlias(key string) bool {
+	for _, alias := range v.Aliases {
+		if key == alias || key == alias+"_FILE" {
+			return true
+		}
+	}
+	return false
+}
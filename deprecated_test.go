@@ -0,0 +1,95 @@
+package envconfig
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type deprecatedSpec struct {
+	Topic    string `alias:"old_topic,legacy_topic" deprecated:"will be removed in v2"`
+	Replicas int    `deprecated:"scaling is automatic"`
+	Region   string `alias:"zone"`
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	t.Parallel()
+	var warnings []*DeprecationWarning
+	h := func(w *DeprecationWarning) { warnings = append(warnings, w) }
+
+	var s deprecatedSpec
+	l := MapLookuper{"APP_TOPIC": "topic", "APP_REGION": "eu"}
+	if err := ProcessWith(l, "app", &s, WithDeprecationHandler(h)); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warning, got %v", warnings)
+	}
+
+	l = MapLookuper{"LEGACY_TOPIC_FILE": "/does/not/exist", "OLD_TOPIC": "old", "APP_REPLICAS": "3", "ZONE": "eu"}
+	if err := ProcessWith(l, "app", &s, WithDeprecationHandler(h)); err != nil {
+		t.Fatal(err)
+	}
+	if s.Topic != "old" || s.Region != "eu" {
+		t.Errorf("unexpected %+v", s)
+	}
+	expected := []*DeprecationWarning{
+		{Key: "OLD_TOPIC", Replacement: "APP_TOPIC", FieldName: "Topic", Path: "Topic", Message: "will be removed in v2"},
+		{Key: "APP_REPLICAS", FieldName: "Replicas", Path: "Replicas", Message: "scaling is automatic"},
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Fatalf("expected %v, got %v", expected, warnings)
+	}
+	if got := warnings[0].String(); got != "envconfig: OLD_TOPIC is deprecated in favor of APP_TOPIC: will be removed in v2" {
+		t.Errorf("unexpected warning %q", got)
+	}
+	if got := warnings[1].String(); got != "envconfig: APP_REPLICAS is deprecated: scaling is automatic" {
+		t.Errorf("unexpected warning %q", got)
+	}
+}
+
+func TestUsageAliases(t *testing.T) {
+	t.Parallel()
+	var s deprecatedSpec
+	buf := new(bytes.Buffer)
+	err := Usagef("app", &s, buf, "{{range .}}{{usage_key .}}|{{usage_aliases .}}|{{usage_deprecated .}}\n{{end}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"APP_TOPIC|OLD_TOPIC, LEGACY_TOPIC|will be removed in v2",
+		"APP_REPLICAS||scaling is automatic",
+		"APP_REGION|ZONE|",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestUsageAliasesAndDeprecations(t *testing.T) {
+	var s struct {
+		Host  string
+		Topic string `alias:"OLD_TOPIC" deprecated:"use APP_TOPIC"`
+	}
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, DefaultListFormat); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"  [aliases]     OLD_TOPIC\n", "  [deprecated]  use APP_TOPIC\n"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in\n%s", expected, buf)
+		}
+	}
+	if strings.Count(buf.String(), "[aliases]") != 1 || strings.Count(buf.String(), "[deprecated]") != 1 {
+		t.Errorf("expected the lines of Topic only in\n%s", buf)
+	}
+
+	buf.Reset()
+	if err := Usagef("app", &s, buf, DefaultTableFormat); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\tREQUIRED\tALIASES\tDEPRECATED\t") {
+		t.Errorf("expected ALIASES and DEPRECATED columns in\n%s", buf)
+	}
+}
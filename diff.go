@@ -0,0 +1,186 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// A Change is a value that differs between two specifications, reported by
+// Diff. Values are rendered as by Dump, masking secrets.
+type Change struct {
+	Key string
+	// Path is the path of the field in the specification, followed by the
+	// index or the key of the element for changes within slices and maps,
+	// such as Hosts[1] or Labels[env].
+	Path string
+	Old  string
+	New  string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s (%s): %q -> %q", c.Path, c.Key, c.Old, c.New)
+}
+
+// Diff lists the values that differ between two specifications of the same
+// type, in field order, followed by the fields that are missing from next,
+// such as those of the elements of slices of structs or of nil pointers to
+// structs. Slices and maps are compared element by element, an element or a
+// field missing on one side being rendered as an empty string. Neither
+// specification is modified.
+func Diff(prefix string, prev, next interface{}, opts ...Option) ([]Change, error) {
+	if reflect.TypeOf(prev) != reflect.TypeOf(next) {
+		return nil, errors.New("envconfig.Diff: specifications of different types")
+	}
+	o := newOptions(opts)
+	o.readOnly = true
+	prevInfos, err := gatherInfo(prefix, prev, o)
+	if err != nil {
+		return nil, err
+	}
+	nextInfos, err := gatherInfo(prefix, next, o)
+	if err != nil {
+		return nil, err
+	}
+
+	// fields are matched by path, as slices of structs may have a different
+	// number of elements
+	prevFields := make(map[string]reflect.Value, len(prevInfos))
+	for _, info := range prevInfos {
+		prevFields[info.Path] = info.Field
+	}
+	var changes []Change
+	for _, info := range nextInfos {
+		d := differ{key: info.Key, secret: info.secret(), tags: info.Tags}
+		d.diff(info.Path, prevFields[info.Path], info.Field)
+		delete(prevFields, info.Path)
+		changes = append(changes, d.changes...)
+	}
+	for _, info := range prevInfos {
+		if field, found := prevFields[info.Path]; found {
+			d := differ{key: info.Key, secret: info.secret(), tags: info.Tags}
+			d.diff(info.Path, field, reflect.Value{})
+			changes = append(changes, d.changes...)
+		}
+	}
+	return changes, nil
+}
+
+// differ compares the values of a field
+type differ struct {
+	key    string
+	secret bool
+	// tags select the JSON encoding or the time layout of values, JSON values
+	// being compared as a whole
+	tags    reflect.StructTag
+	changes []Change
+}
+
+func (d *differ) diff(path string, prev, next reflect.Value) {
+	prev, next = indirect(prev), indirect(next)
+	kind := compositeKind(prev)
+	if isJSON(d.tags) || kind == reflect.Invalid || kind != compositeKind(next) {
+		d.compare(path, prev, next)
+		return
+	}
+
+	if kind == reflect.Slice {
+		n := prev.Len()
+		if next.Len() > n {
+			n = next.Len()
+		}
+		for i := 0; i < n; i++ {
+			d.diff(path+"["+strconv.Itoa(i)+"]", index(prev, i), index(next, i))
+		}
+		return
+	}
+
+	keys := make(map[string]reflect.Value)
+	for _, k := range append(prev.MapKeys(), next.MapKeys()...) {
+		keys[renderValue(k, defaultSeparators)] = k
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d.diff(path+"["+name+"]", prev.MapIndex(keys[name]), next.MapIndex(keys[name]))
+	}
+}
+
+// compare adds a change if the rendered values differ
+func (d *differ) compare(path string, prev, next reflect.Value) {
+	prevValue, nextValue := d.render(prev), d.render(next)
+	if prevValue == nextValue {
+		return
+	}
+	d.changes = append(d.changes, Change{
+		Key:  d.key,
+		Path: path,
+		Old:  redact(d.secret, prevValue),
+		New:  redact(d.secret, nextValue),
+	})
+}
+
+// render renders a value, or an empty string for a missing value
+func (d *differ) render(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if info := (varInfo{Field: v, Tags: d.tags}); isJSON(d.tags) || info.layout() != "" {
+		return info.render()
+	}
+	return renderValue(v, defaultSeparators)
+}
+
+// indirect dereferences non-nil pointers
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr && !v.IsNil() && v.Type() != locationPtrType {
+		v = v.Elem()
+	}
+	return v
+}
+
+// index returns the element i of a slice, or an invalid value if it is too short
+func index(v reflect.Value, i int) reflect.Value {
+	if i >= v.Len() {
+		return reflect.Value{}
+	}
+	return v.Index(i)
+}
+
+// compositeKind returns the kind of slices and maps compared element by
+// element, and reflect.Invalid for other values, including byte slices and
+// types with their own encoding
+func compositeKind(v reflect.Value) reflect.Kind {
+	if !v.IsValid() {
+		return reflect.Invalid
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return reflect.Invalid
+		}
+	case reflect.Map:
+	default:
+		return reflect.Invalid
+	}
+	if !v.CanAddr() {
+		// interface lookups need an addressable value
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+	if encoderFrom(v) != nil || decoderFrom(v) != nil || setterFrom(v) != nil ||
+		textMarshaler(v) != nil || binaryMarshaler(v) != nil {
+		return reflect.Invalid
+	}
+	return v.Kind()
+}
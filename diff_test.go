@@ -0,0 +1,76 @@
+package envconfig
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type diffSpec struct {
+	Port     int
+	Password string `secret:"true"`
+	Hosts    []string
+	Labels   map[string]string
+	Timeout  *time.Duration
+	Nested   struct {
+		Weights map[string][]int
+	}
+	TLS *tlsSpec
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	second := time.Second
+	prev := diffSpec{
+		Port:     80,
+		Password: "old",
+		Hosts:    []string{"a", "b", "c"},
+		Labels:   map[string]string{"env": "dev", "team": "core"},
+	}
+	prev.Nested.Weights = map[string][]int{"a": {1, 2}}
+	next := diffSpec{
+		Port:     80,
+		Password: "new",
+		Hosts:    []string{"a", "d"},
+		Labels:   map[string]string{"env": "prod", "team": "core", "zone": "eu"},
+		Timeout:  &second,
+		TLS:      &tlsSpec{Cert: "cert.pem"},
+	}
+	next.Nested.Weights = map[string][]int{"a": {1, 3}}
+
+	changes, err := Diff("app", &prev, &next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{
+		{Key: "APP_PASSWORD", Path: "Password", Old: "******", New: "******"},
+		{Key: "APP_HOSTS", Path: "Hosts[1]", Old: "b", New: "d"},
+		{Key: "APP_HOSTS", Path: "Hosts[2]", Old: "c", New: ""},
+		{Key: "APP_LABELS", Path: "Labels[env]", Old: "dev", New: "prod"},
+		{Key: "APP_LABELS", Path: "Labels[zone]", Old: "", New: "eu"},
+		{Key: "APP_TIMEOUT", Path: "Timeout", Old: "", New: "1s"},
+		{Key: "APP_NESTED_WEIGHTS", Path: "Nested.Weights[a][1]", Old: "2", New: "3"},
+		{Key: "APP_TLS_CERT", Path: "TLS.Cert", Old: "", New: "cert.pem"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, changes)
+	}
+
+	if prev.TLS != nil {
+		t.Errorf("expected the nil pointer to be left alone, got %+v", prev.TLS)
+	}
+	changes, err = Diff("app", &next, &prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := changes[len(changes)-1]; last.Path != "TLS.Cert" || last.Old != "cert.pem" || last.New != "" {
+		t.Errorf("expected the removed TLS.Cert last, got %v", changes)
+	}
+
+	if changes, err := Diff("app", &prev, &prev); err != nil || len(changes) != 0 {
+		t.Errorf("expected no change, got %v, %v", changes, err)
+	}
+	if _, err := Diff("app", &prev, &Specification{}); err == nil {
+		t.Error("expected an error for specifications of different types")
+	}
+}
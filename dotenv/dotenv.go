@@ -0,0 +1,314 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package dotenv loads .env files as an envconfig source, without modifying
+// the process environment.
+//
+// The usual dotenv grammar is supported:
+//
+//	# comments, and blank lines, are ignored
+//	export KEY=value          # an optional export prefix, and trailing comments
+//	RAW='no $expansion \n or escapes'
+//	QUOTED="escapes (\n, \t, \", \\, \$) and ${KEY} or $KEY expansion"
+//	MULTI="values can span
+//	several lines when quoted"
+//
+// Variables are expanded with the values defined earlier in the file, or
+// else with the values of the source the file is layered with.
+package dotenv
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/objenious/envconfig"
+)
+
+// A SyntaxError occurs when a dotenv file cannot be parsed.
+type SyntaxError struct {
+	Filename string
+	Line     int
+	Msg      string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Filename != "" {
+		return fmt.Sprintf("dotenv: %s:%d: %s", e.Filename, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("dotenv: line %d: %s", e.Line, e.Msg)
+}
+
+// Option configures how a dotenv file is loaded.
+type Option func(*options)
+
+type options struct {
+	lookuper envconfig.Lookuper
+	override bool
+}
+
+// Override gives the values of the file precedence over the values of the
+// source it is layered with. By default, the file only provides the values
+// that are not already set.
+func Override() Option {
+	return func(o *options) { o.override = true }
+}
+
+// WithLookuper sets the source the file is layered with, and which is used to
+// expand variables not defined in the file. It defaults to the process
+// environment.
+func WithLookuper(l envconfig.Lookuper) Option {
+	return func(o *options) { o.lookuper = l }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{lookuper: envconfig.OsLookuper()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Load parses the dotenv file at path, and returns a Lookuper layering its
+// values with the process environment, which takes precedence unless the
+// Override option is used. Values found in the file are reported as coming
+// from path.
+func Load(path string, opts ...Option) (envconfig.Lookuper, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values, err := Parse(f, opts...)
+	if err != nil {
+		if serr, ok := err.(*SyntaxError); ok {
+			serr.Filename = path
+		}
+		return nil, err
+	}
+
+	file := envconfig.Named(path, values)
+	o := newOptions(opts)
+	if o.override {
+		return envconfig.MultiLookuper(file, o.lookuper), nil
+	}
+	return envconfig.MultiLookuper(o.lookuper, file), nil
+}
+
+// Parse reads dotenv content from r.
+func Parse(r io.Reader, opts ...Option) (envconfig.MapLookuper, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{
+		src:      strings.Replace(string(b), "\r\n", "\n", -1),
+		line:     1,
+		values:   envconfig.MapLookuper{},
+		lookuper: newOptions(opts).lookuper,
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.values, nil
+}
+
+type parser struct {
+	src      string
+	pos      int
+	line     int
+	values   envconfig.MapLookuper
+	lookuper envconfig.Lookuper
+}
+
+func (p *parser) errorf(line int, format string, args ...interface{}) error {
+	return &SyntaxError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parse() error {
+	for p.pos < len(p.src) {
+		p.skipSpaces()
+		switch {
+		case p.pos >= len(p.src):
+			return nil
+		case p.src[p.pos] == '\n':
+			p.pos++
+			p.line++
+			continue
+		case p.src[p.pos] == '#':
+			p.skipLine()
+			continue
+		}
+
+		line := p.line
+		key := p.readKey()
+		if key == "export" && p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+			p.skipSpaces()
+			key = p.readKey()
+		}
+		if key == "" {
+			return p.errorf(line, "invalid variable name")
+		}
+		p.skipSpaces()
+		if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+			return p.errorf(line, "missing = after %s", key)
+		}
+		p.pos++
+		p.skipSpaces()
+
+		value, err := p.readValue()
+		if err != nil {
+			return err
+		}
+		p.values[key] = value
+	}
+	return nil
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipLine moves to the start of the next line
+func (p *parser) skipLine() {
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+func (p *parser) readKey() string {
+	start := p.pos
+	for p.pos < len(p.src) && isKeyChar(p.src[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func isKeyChar(c byte, first bool) bool {
+	switch {
+	case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		return true
+	case '0' <= c && c <= '9':
+		return !first
+	}
+	return false
+}
+
+func (p *parser) readValue() (string, error) {
+	if p.pos >= len(p.src) {
+		return "", nil
+	}
+	switch quote := p.src[p.pos]; quote {
+	case '\'', '"':
+		line := p.line
+		p.pos++
+		start := p.pos
+		for ; p.pos < len(p.src) && p.src[p.pos] != quote; p.pos++ {
+			switch p.src[p.pos] {
+			case '\\':
+				if quote == '"' && p.pos+1 < len(p.src) {
+					p.pos++
+					if p.src[p.pos] == '\n' {
+						p.line++
+					}
+				}
+			case '\n':
+				p.line++
+			}
+		}
+		if p.pos >= len(p.src) {
+			return "", p.errorf(line, "unterminated quoted value")
+		}
+		raw := p.src[start:p.pos]
+		p.pos++
+		if err := p.endOfLine(); err != nil {
+			return "", err
+		}
+		if quote == '\'' {
+			return raw, nil
+		}
+		return p.expand(raw, true, line)
+	}
+
+	start := p.pos
+	p.skipLine()
+	raw := p.src[start:p.pos]
+	for i := 1; i < len(raw); i++ {
+		if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			raw = raw[:i]
+			break
+		}
+	}
+	return p.expand(strings.TrimRight(raw, " \t"), false, p.line)
+}
+
+// endOfLine checks that nothing but a comment follows a quoted value
+func (p *parser) endOfLine() error {
+	p.skipSpaces()
+	if p.pos >= len(p.src) || p.src[p.pos] == '\n' {
+		return nil
+	}
+	if p.src[p.pos] != '#' {
+		return p.errorf(p.line, "unexpected characters after quoted value")
+	}
+	p.skipLine()
+	return nil
+}
+
+var escapes = map[byte]string{
+	'n':  "\n",
+	'r':  "\r",
+	't':  "\t",
+	'"':  "\"",
+	'\\': "\\",
+	'$':  "$",
+}
+
+// expand replaces ${KEY} and $KEY in raw, and escape sequences if escaped is set
+func (p *parser) expand(raw string, escaped bool, line int) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\\' && escaped && i+1 < len(raw):
+			i++
+			if s, ok := escapes[raw[i]]; ok {
+				b.WriteString(s)
+			} else {
+				b.WriteByte(c)
+				b.WriteByte(raw[i])
+			}
+		case c == '$' && i+1 < len(raw) && raw[i+1] == '{':
+			end := strings.IndexByte(raw[i:], '}')
+			if end < 0 {
+				return "", p.errorf(line, "unterminated variable reference")
+			}
+			b.WriteString(p.lookup(raw[i+2 : i+end]))
+			i += end
+		case c == '$' && i+1 < len(raw) && isKeyChar(raw[i+1], true):
+			j := i + 1
+			for j < len(raw) && isKeyChar(raw[j], j == i+1) {
+				j++
+			}
+			b.WriteString(p.lookup(raw[i+1 : j]))
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+func (p *parser) lookup(key string) string {
+	if v, ok := p.values[key]; ok {
+		return v
+	}
+	v, _ := p.lookuper.Lookup(key)
+	return v
+}
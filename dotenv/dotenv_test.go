@@ -0,0 +1,120 @@
+package dotenv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/objenious/envconfig"
+)
+
+func TestParse(t *testing.T) {
+	src := `# a comment
+PLAIN=value
+export EXPORTED=yes
+SPACED = around equals  # trailing comment
+HASH=a#b
+EMPTY=
+SINGLE='no $PLAIN \n here'
+DOUBLE="tab\there \"quoted\" \$PLAIN"
+EXPANDED=${PLAIN}-$PLAIN-${FROM_ENV}
+MULTI="first
+second"
+WINDOWS=crlf` + "\r\n"
+
+	values, err := Parse(strings.NewReader(src), WithLookuper(envconfig.MapLookuper{"FROM_ENV": "env"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := envconfig.MapLookuper{
+		"PLAIN":    "value",
+		"EXPORTED": "yes",
+		"SPACED":   "around equals",
+		"HASH":     "a#b",
+		"EMPTY":    "",
+		"SINGLE":   `no $PLAIN \n here`,
+		"DOUBLE":   "tab\there \"quoted\" $PLAIN",
+		"EXPANDED": "value-value-env",
+		"MULTI":    "first\nsecond",
+		"WINDOWS":  "crlf",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+		msg  string
+	}{
+		{"A=1\nB\n", 2, "missing = after B"},
+		{"A=1\n\n=2\n", 3, "invalid variable name"},
+		{"A=1\nB=\"open\n\nC=3\n", 2, "unterminated quoted value"},
+		{"A='x' y\n", 1, "unexpected characters after quoted value"},
+		{"A=${B\n", 1, "unterminated variable reference"},
+	}
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.src), WithLookuper(envconfig.MapLookuper{}))
+		v, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("%q: expected SyntaxError, got %T %v", test.src, err, err)
+			continue
+		}
+		if v.Line != test.line || v.Msg != test.msg {
+			t.Errorf("%q: expected line %d: %s, got %v", test.src, test.line, test.msg, v)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	if err := ioutil.WriteFile(path, []byte("APP_PORT=8080\nAPP_HOST=localhost\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var s struct {
+		Port int
+		Host string
+	}
+	env := envconfig.MapLookuper{"APP_HOST": "example.com"}
+
+	l, err := Load(path, WithLookuper(env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := envconfig.ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Port != 8080 || s.Host != "example.com" {
+		t.Errorf("expected 8080 and example.com, got %d and %s", s.Port, s.Host)
+	}
+
+	l, err = Load(path, WithLookuper(env), Override())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := envconfig.ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Host != "localhost" {
+		t.Errorf("expected localhost, got %s", s.Host)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("APP_PORT=8080\nAPP_HOST\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load(path)
+	if experr := "dotenv: " + path + ":2: missing = after APP_HOST"; err == nil || err.Error() != experr {
+		t.Errorf("expected %s, got %v", experr, err)
+	}
+}
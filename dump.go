@@ -0,0 +1,64 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// secretMask replaces the values of fields tagged with `secret:"true"`
+const secretMask = "******"
+
+// Dump renders a processed specification, with a KEY=value line per field.
+// The values of fields tagged with `secret:"true"` are masked, so that the
+// result can be logged safely. spec is left untouched, the fields of nil
+// pointers to structs being omitted.
+func Dump(prefix string, spec interface{}, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	o.readOnly = true
+	infos, err := gatherInfo(prefix, spec, o)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, len(infos))
+	for i, info := range infos {
+		lines[i] = info.Key + "=" + info.redactedValue()
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// redactedValue renders the value of the field, masking secrets
+func (v varInfo) redactedValue() string {
+	return redact(v.secret(), v.render())
+}
+
+// redact masks value if it is a secret
+func redact(secret bool, value string) string {
+	if secret && value != "" {
+		return secretMask
+	}
+	return value
+}
+
+// renderValue formats the value of a field as Export does, nil pointers being
+// rendered empty
+func renderValue(field reflect.Value, seps separators) string {
+	if s, _, err := encodeField(field, seps); err == nil {
+		return s
+	}
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+	if !field.CanInterface() {
+		return ""
+	}
+	return fmt.Sprint(field.Interface())
+}
@@ -0,0 +1,75 @@
+package envconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type secretSpec struct {
+	User     string
+	Password string `secret:"true" default:"changeme"`
+	Token    string `secret:"true"`
+	Port     *int
+	Timeout  time.Duration
+}
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+	s := secretSpec{User: "admin", Password: "s3cr3t", Timeout: time.Minute}
+	out, err := Dump("app", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "APP_USER=admin\nAPP_PASSWORD=******\nAPP_TOKEN=\nAPP_PORT=\nAPP_TIMEOUT=1m0s"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestDumpReadOnly(t *testing.T) {
+	t.Parallel()
+	var s struct {
+		Port int
+		TLS  *struct {
+			Cert string
+		}
+	}
+	out, err := Dump("app", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "APP_PORT=0" || s.TLS != nil {
+		t.Errorf("expected the nil pointer to be left alone, got %q and %+v", out, s.TLS)
+	}
+}
+
+func TestParseErrorRedaction(t *testing.T) {
+	t.Parallel()
+	var s struct {
+		Pin int `secret:"true"`
+	}
+	err := ProcessWith(MapLookuper{"APP_PIN": "12ab"}, "app", &s)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strings.Contains(err.Error(), "12ab") {
+		t.Errorf("secret value found in %q", err)
+	}
+	if v, ok := err.(*ParseError); !ok || v.Value != "12ab" {
+		t.Errorf("expected ParseError with value, got %#v", err)
+	}
+}
+
+func TestUsageRedaction(t *testing.T) {
+	t.Parallel()
+	var s secretSpec
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{usage_key .}}={{usage_default .}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "APP_PASSWORD=******\n") {
+		t.Errorf("expected a masked default, got %q", buf)
+	}
+}
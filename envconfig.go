@@ -8,9 +8,8 @@ import (
 	"encoding"
 	"errors"
 	"fmt"
-	"os"
+	"io/ioutil"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -19,8 +18,6 @@ import (
 // ErrInvalidSpecification indicates that a specification is of the wrong type.
 var ErrInvalidSpecification = errors.New("specification must be a struct pointer")
 
-var gatherRegexp = regexp.MustCompile("([^A-Z]+|[A-Z][^A-Z]+|[A-Z]+)")
-
 // A ParseError occurs when an environment variable cannot be converted to
 // the type required by a struct field during assignment.
 type ParseError struct {
@@ -29,6 +26,13 @@ type ParseError struct {
 	TypeName  string
 	Value     string
 	Err       error
+	// Offset is the offset of the error in Value when Err is a JSON decoding
+	// error, for fields tagged with `format:"json"`.
+	Offset int64
+
+	// secret is set for fields tagged with `secret:"true"`, whose value is
+	// masked in the error message
+	secret bool
 }
 
 // Decoder has the same semantics as Setter, but takes higher precedence.
@@ -44,33 +48,125 @@ type Setter interface {
 }
 
 func (e *ParseError) Error() string {
-	return fmt.Sprintf("envconfig.Process: assigning %[1]s to %[2]s: converting '%[3]s' to type %[4]s. details: %[5]s", e.KeyName, e.FieldName, e.Value, e.TypeName, e.Err)
+	value, details := e.Value, fmt.Sprint(e.Err)
+	if e.secret && value != "" {
+		// the underlying error may quote the value as well
+		details = strings.Replace(details, value, secretMask, -1)
+		value = secretMask
+	}
+	if offset, ok := jsonOffset(e.Err); ok {
+		details += fmt.Sprintf(" at offset %d", offset)
+	}
+	return fmt.Sprintf("envconfig.Process: assigning %[1]s to %[2]s: converting '%[3]s' to type %[4]s. details: %[5]s", e.KeyName, e.FieldName, value, e.TypeName, details)
 }
 
 // varInfo maintains information about the configuration variable
 type varInfo struct {
 	Name  string
+	Path  string
 	Alt   []string
 	Key   string
 	Field reflect.Value
 	Tags  reflect.StructTag
+
+	// Aliases are the names listed in the alias tag
+	Aliases []string
+
+	// Conditions are the required_if and required_with tags, and Exclusive
+	// the keys of the exclusive group of the field, set by resolveConditions
+	Conditions []condition
+	Exclusive  []string
+
+	// Files are the _FILE variants of Key and Aliases looked up for the
+	// field, set by resolveFiles
+	Files []string
+
+	// Err is an error found while gathering the field, such as an invalid
+	// number of elements, reported when processing it
+	Err error
+}
+
+// parseError returns a ParseError for value, found under key
+func (v varInfo) parseError(key, value string, err error) *ParseError {
+	offset, _ := jsonOffset(err)
+	return &ParseError{
+		KeyName:   key,
+		FieldName: v.Name,
+		TypeName:  v.Field.Type().String(),
+		Value:     value,
+		Err:       err,
+		Offset:    offset,
+		secret:    v.secret(),
+	}
+}
+
+// secret reports whether the field is tagged with `secret:"true"`
+func (v varInfo) secret() bool {
+	return isTrue(v.Tags.Get("secret"))
+}
+
+// alternatives returns the keys looked up when Key is not set, in order
+func (v varInfo) alternatives() []string {
+	var alts []string
+	seen := map[string]struct{}{v.Key: {}}
+	for _, alt := range v.Alt {
+		if _, found := seen[alt]; found {
+			continue
+		}
+		seen[alt] = struct{}{}
+		alts = append(alts, alt)
+	}
+	return alts
+}
+
+// structInfo maintains information about a struct holding configuration variables
+type structInfo struct {
+	Path   string
+	Prefix string
+	Value  reflect.Value
+
+	// Map and MapKey are set for the elements of maps of structs, to store
+	// them back once processed
+	Map    reflect.Value
+	MapKey reflect.Value
 }
 
 // GatherInfo gathers information about the specified struct
-func gatherInfo(prefix string, spec interface{}) ([]varInfo, error) {
+func gatherInfo(prefix string, spec interface{}, o *options) ([]varInfo, error) {
+	infos, _, err := gatherSpec(prefix, spec, o)
+	return infos, err
+}
+
+// gatherSpec gathers information about the specified struct, and lists it
+// along with its nested structs, innermost first
+func gatherSpec(prefix string, spec interface{}, o *options) ([]varInfo, []structInfo, error) {
+	infos, structs, err := gatherStruct(prefix, spec, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := resolveConditions(infos); err != nil {
+		return nil, nil, err
+	}
+	resolveFiles(infos)
+	return infos, structs, nil
+}
+
+// gatherStruct gathers information about a struct and its nested structs
+func gatherStruct(prefix string, spec interface{}, o *options) ([]varInfo, []structInfo, error) {
 	s := reflect.ValueOf(spec)
 
 	if s.Kind() != reflect.Ptr {
-		return nil, ErrInvalidSpecification
+		return nil, nil, ErrInvalidSpecification
 	}
 	s = s.Elem()
 	if s.Kind() != reflect.Struct {
-		return nil, ErrInvalidSpecification
+		return nil, nil, ErrInvalidSpecification
 	}
 	typeOfSpec := s.Type()
 
 	// over allocate an info array, we will extend if needed later
 	infos := make([]varInfo, 0, s.NumField())
+	var structs []structInfo
 	for i := 0; i < s.NumField(); i++ {
 		f := s.Field(i)
 		ftype := typeOfSpec.Field(i)
@@ -78,10 +174,17 @@ func gatherInfo(prefix string, spec interface{}) ([]varInfo, error) {
 			continue
 		}
 
-		for f.Kind() == reflect.Ptr {
+		// time zones are values rather than structs with fields
+		skip := false
+		for f.Kind() == reflect.Ptr && f.Type() != locationPtrType {
 			if f.IsNil() {
-				if f.Type().Elem().Kind() != reflect.Struct {
-					// nil pointer to a non-struct: leave it alone
+				if f.Type().Elem().Kind() != reflect.Struct || isJSON(ftype.Tag) {
+					// nil pointer to a non-struct or to a JSON value: leave it alone
+					break
+				}
+				if o.readOnly {
+					// nothing to render
+					skip = true
 					break
 				}
 				// nil pointer to struct: create a zero instance
@@ -89,10 +192,14 @@ func gatherInfo(prefix string, spec interface{}) ([]varInfo, error) {
 			}
 			f = f.Elem()
 		}
+		if skip {
+			continue
+		}
 
 		// Capture information about the config variable
 		info := varInfo{
 			Name:  ftype.Name,
+			Path:  ftype.Name,
 			Field: f,
 			Tags:  ftype.Tag,
 			Alt:   generateAlternatives(strings.ToUpper(ftype.Tag.Get("envconfig")), ftype.Name),
@@ -104,18 +211,8 @@ func gatherInfo(prefix string, spec interface{}) ([]varInfo, error) {
 		}
 
 		// Best effort to un-pick camel casing as separate words
-		if isTrue(ftype.Tag.Get("split_words")) {
-			words := gatherRegexp.FindAllStringSubmatch(ftype.Name, -1)
-			if len(words) > 0 {
-				var name []string
-				for _, words := range words {
-					if words[0] != "" {
-						name = append(name, words[0])
-					}
-				}
-
-				info.Key = strings.Join(name, "_")
-			}
+		if split, ok := ftype.Tag.Lookup("split_words"); isTrue(split) || (o.splitWords && !ok) {
+			info.Key = o.nameMapper(ftype.Name)
 		}
 		if info.Alt[0] != "" {
 			info.Key = info.Alt[0]
@@ -131,25 +228,75 @@ func gatherInfo(prefix string, spec interface{}) ([]varInfo, error) {
 			info.Alt = generateAlternatives(strings.ToUpper(info.Key), getVarName(ftype.Name, ftype.Tag.Get("envconfig")))
 		}
 		info.Key = strings.ToUpper(info.Key)
+
+		// Strict fields are only looked up under their key and aliases
+		strict := o.strict
+		if tag, ok := ftype.Tag.Lookup("strict"); ok {
+			strict = isTrue(tag)
+		}
+		info.Aliases = parseAliases(ftype.Tag.Get("alias"))
+		if strict {
+			info.Alt = append([]string{info.Key}, info.Aliases...)
+		} else {
+			info.Alt = append(append([]string{info.Alt[0]}, info.Aliases...), info.Alt[1:]...)
+		}
 		infos = append(infos, info)
 
+		if isJSON(ftype.Tag) {
+			// JSON values are assigned as a whole
+			continue
+		}
+
+		if isIndexed(f) || isKeyed(f) {
+			gather := gatherSlice
+			if f.Kind() == reflect.Map {
+				gather = gatherMap
+			}
+			elemInfos, elemStructs, err := gather(info, f, o)
+			if err != nil {
+				return nil, nil, err
+			}
+			infos = append(infos[:len(infos)-1], elemInfos...)
+			structs = append(structs, elemStructs...)
+			continue
+		}
+
 		if f.Kind() == reflect.Struct {
 			// honor Decode if present
 			if decoderFrom(f) == nil && setterFrom(f) == nil && textUnmarshaler(f) == nil && binaryUnmarshaler(f) == nil {
 				innerPrefix := info.Alt[0]
 
+				innerOptions := *o
+				innerOptions.strict = strict
+
 				embeddedPtr := f.Addr().Interface()
-				embeddedInfos, err := gatherInfo(innerPrefix, embeddedPtr)
+				embeddedInfos, embeddedStructs, err := gatherStruct(innerPrefix, embeddedPtr, &innerOptions)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
+				}
+				for i := range embeddedInfos {
+					embeddedInfos[i].Path = info.Path + "." + embeddedInfos[i].Path
+				}
+				for i := range embeddedStructs {
+					embeddedStructs[i].Path = joinPath(info.Path, embeddedStructs[i].Path)
 				}
 				infos = append(infos[:len(infos)-1], embeddedInfos...)
+				structs = append(structs, embeddedStructs...)
 
 				continue
 			}
 		}
 	}
-	return infos, nil
+	structs = append(structs, structInfo{Prefix: strings.ToUpper(prefix), Value: s})
+	return infos, structs, nil
+}
+
+// joinPath joins the path of a struct field to the path of a nested field
+func joinPath(path, nested string) string {
+	if nested == "" {
+		return path
+	}
+	return path + "." + nested
 }
 
 func getVarName(varname, vartag string) string {
@@ -159,6 +306,17 @@ func getVarName(varname, vartag string) string {
 	return strings.ToUpper(varname)
 }
 
+// parseAliases parses the comma separated names of an alias tag
+func parseAliases(tag string) []string {
+	var aliases []string
+	for _, alias := range strings.Split(tag, ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, strings.ToUpper(alias))
+		}
+	}
+	return aliases
+}
+
 func generateAlternatives(matrice, name string) []string {
 	alts := []string{matrice}
 	split := strings.Split(matrice, "_")
@@ -178,90 +336,297 @@ func generateAlternatives(matrice, name string) []string {
 // CheckDisallowed checks that no environment variables with the prefix are set
 // that we don't know how or want to parse. This is likely only meaningful with
 // a non-empty prefix.
-func CheckDisallowed(prefix string, spec interface{}) error {
-	infos, err := gatherInfo(prefix, spec)
+//
+// Every unknown variable is reported as an UnknownVariableError, suggesting
+// the closest key or alias of the specification. When there is more than one,
+// the returned error is an Errors value. Variables matching the patterns given
+// with WithAllowed are not reported.
+func CheckDisallowed(prefix string, spec interface{}, opts ...Option) error {
+	return CheckDisallowedWith(OsLookuper(), prefix, spec, opts...)
+}
+
+// CheckDisallowedWith is the same as CheckDisallowed but checks the keys listed
+// by l, which must implement Environer.
+func CheckDisallowedWith(l Lookuper, prefix string, spec interface{}, opts ...Option) error {
+	environer, ok := l.(Environer)
+	if !ok {
+		return ErrNotEnumerable
+	}
+
+	o := newOptions(opts)
+	o.lookuper = l
+	infos, err := gatherInfo(prefix, spec, o)
 	if err != nil {
 		return err
 	}
 
 	vars := make(map[string]struct{})
+	var keys []string
 	for _, info := range infos {
-		vars[info.Key] = struct{}{}
+		keys = append(keys, info.Key)
+		keys = append(keys, info.Aliases...)
+		for _, name := range append(append([]string{info.Key}, info.alternatives()...), info.Files...) {
+			vars[name] = struct{}{}
+		}
 	}
 
 	if prefix != "" {
 		prefix = strings.ToUpper(prefix) + "_"
 	}
 
-	for _, env := range os.Environ() {
+	var errs Errors
+	for _, env := range environer.Environ() {
 		if !strings.HasPrefix(env, prefix) {
 			continue
 		}
 		v := strings.SplitN(env, "=", 2)[0]
-		if _, found := vars[v]; !found {
-			return fmt.Errorf("unknown environment variable %s", v)
+		if _, found := vars[v]; found || o.allowed(v) {
+			continue
 		}
+		errs = append(errs, &UnknownVariableError{Key: v, Suggestion: closest(v, keys, len(v)-len(prefix))})
 	}
+	return errorOrNil(errs)
+}
 
-	return nil
+// closest returns the key closest to v, if it is close enough to be a likely
+// misspelling of the n last characters of v
+func closest(v string, keys []string, n int) string {
+	best, bestDistance := "", n/3
+	if bestDistance < 1 {
+		bestDistance = 1
+	}
+	for _, key := range keys {
+		if d := editDistance(v, key); d <= bestDistance && (best == "" || d < bestDistance) {
+			best, bestDistance = key, d
+		}
+	}
+	return best
 }
 
 // Process populates the specified struct based on environment variables
-func Process(prefix string, spec interface{}) error {
-	infos, err := gatherInfo(prefix, spec)
+func Process(prefix string, spec interface{}, opts ...Option) error {
+	return ProcessWith(OsLookuper(), prefix, spec, opts...)
+}
 
-	for _, info := range infos {
+// ProcessWith populates the specified struct based on the values found in l,
+// using the same key names and alternatives as Process.
+//
+// Conditional requirements (required_if, required_with and exclusive groups)
+// are checked once every field is processed. Then the specification and its
+// nested structs implementing Validator are validated, unless one of their
+// fields is invalid already.
+//
+// Processing does not stop at the first invalid field: every missing required
+// key and every ParseError is reported. When there is more than one, the
+// returned error is an Errors value.
+func ProcessWith(l Lookuper, prefix string, spec interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	o.lookuper = l
+	if o.report != nil {
+		*o.report = (*o.report)[:0]
+	}
+	infos, structs, err := gatherSpec(prefix, spec, o)
+	if err != nil {
+		return err
+	}
 
-		// `os.Getenv` cannot differentiate between an explicitly set empty value
-		// and an unset value. `os.LookupEnv` is preferred to `syscall.Getenv`,
-		// but it is only available in go1.5 or newer. We're using Go build tags
-		// here to use os.LookupEnv for >=go1.5
-		value, ok := lookupEnv(info.Key)
-		if !ok {
-			for _, alt := range info.Alt {
-				value, ok = lookupEnv(alt)
-				if ok {
-					break
-				}
-			}
+	var errs Errors
+	var failed []string
+	statuses := make([]fieldStatus, len(infos))
+	for i, info := range infos {
+		statuses[i], err = processInfo(l, info, o)
+		if err == nil && statuses[i] != statusUnset {
+			err = validateField(info)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			failed = append(failed, info.Path)
+		}
+	}
+	for _, s := range structs {
+		if s.Map.IsValid() {
+			s.Map.SetMapIndex(s.MapKey, s.Value)
 		}
+	}
+	errs = append(errs, checkConditions(infos, statuses)...)
+	errs = append(errs, validateStructs(structs, failed)...)
 
-		def := info.Tags.Get("default")
-		if def != "" && !ok {
-			value = def
+	return errorOrNil(errs)
+}
+
+// processInfo looks up the value of a field and assigns it, reporting whether
+// a value or the default was found, even if it could not be assigned
+func processInfo(l Lookuper, info varInfo, o *options) (fieldStatus, error) {
+	m, ok, err := lookup(l, info, o.report != nil)
+	def := info.Tags.Get("default")
+	o.report.add(info, m, ok, def != "")
+	if ok {
+		if w := info.deprecation(m.key); w != nil {
+			o.deprecation(w)
 		}
+	}
+	if info.Err != nil {
+		return statusSet, info.Err
+	}
+	if err != nil {
+		return statusSet, info.parseError(m.key, m.value, err)
+	}
+
+	status := statusSet
+	if def != "" && !ok {
+		m.value = def
+		status = statusDefault
+	}
 
-		req := info.Tags.Get("required")
-		if !ok && def == "" {
-			if isTrue(req) {
-				return fmt.Errorf("required key %s missing value", info.Key)
+	req := info.Tags.Get("required")
+	if !ok && def == "" {
+		if isTrue(req) {
+			return statusUnset, &RequiredError{
+				Key:          info.Key,
+				FieldName:    info.Name,
+				Path:         info.Path,
+				Alternatives: info.alternatives(),
 			}
+		}
+		return statusUnset, nil
+	}
+
+	value := m.value
+	if isTrue(info.Tags.Get("file")) {
+		value, err = readFile(m.value)
+		if err != nil {
+			return status, info.parseError(m.key, m.value, err)
+		}
+	}
+
+	err = info.assign(value)
+	if err != nil {
+		return status, info.parseError(m.key, value, err)
+	}
+	return status, nil
+}
+
+// match holds the value found for a field
+type match struct {
+	value    string
+	key      string
+	source   Lookuper
+	shadowed []string
+}
+
+// lookup returns the value of the first key of info set in l.
+// The key and aliases are followed by their _FILE variant, if listed in Files,
+// holding the path of a file to read the value from.
+// If shadowed is set, the keys that are also set but come later are listed.
+func lookup(l Lookuper, info varInfo, shadowed bool) (m match, ok bool, err error) {
+	type candidate struct {
+		key  string
+		file bool
+	}
+	var candidates []candidate
+	for _, key := range append([]string{info.Key}, info.alternatives()...) {
+		candidates = append(candidates, candidate{key: key})
+		if contains(info.Files, key+"_FILE") {
+			candidates = append(candidates, candidate{key: key + "_FILE", file: true})
+		}
+	}
+
+	m.key = info.Key
+	var file bool
+	for _, c := range candidates {
+		value, source, found := lookupSource(l, c.key)
+		if !found {
 			continue
 		}
+		if ok {
+			m.shadowed = append(m.shadowed, c.key)
+			continue
+		}
+		m.value, m.key, m.source, ok, file = value, c.key, source, true, c.file
+		if !shadowed {
+			break
+		}
+	}
 
-		err = processField(value, info.Field)
+	if file {
+		value, err := readFile(m.value)
 		if err != nil {
-			return &ParseError{
-				KeyName:   info.Key,
-				FieldName: info.Name,
-				TypeName:  info.Field.Type().String(),
-				Value:     value,
-				Err:       err,
+			return m, true, err
+		}
+		m.value = value
+	}
+	return m, ok, nil
+}
+
+// resolveFiles lists the _FILE variants looked up for each field: those of its
+// key and aliases, unless the field is tagged with `file:"true"`. A variant
+// that is a name looked up for another field, such as APP_LOG_FILE for the Log
+// and LogFile fields, is left out.
+func resolveFiles(infos []varInfo) {
+	owners := nameOwners(infos)
+	for i := range infos {
+		info := &infos[i]
+		for _, name := range info.fileCandidates() {
+			if len(otherPaths(owners[name], info.Path)) == 0 {
+				info.Files = append(info.Files, name)
 			}
 		}
 	}
+}
+
+// fileCandidates returns the _FILE variants of the key and aliases of a field
+// not tagged with `file:"true"`
+func (v varInfo) fileCandidates() []string {
+	if isTrue(v.Tags.Get("file")) {
+		return nil
+	}
+	var names []string
+	for _, name := range append([]string{v.Key}, v.Aliases...) {
+		names = append(names, name+"_FILE")
+	}
+	return names
+}
+
+// nameOwners maps the names looked up for fields to the paths of the fields
+func nameOwners(infos []varInfo) map[string][]string {
+	owners := make(map[string][]string)
+	for _, info := range infos {
+		for _, name := range append([]string{info.Key}, info.alternatives()...) {
+			owners[name] = append(owners[name], info.Path)
+		}
+	}
+	return owners
+}
+
+// otherPaths lists the paths that are not path
+func otherPaths(paths []string, path string) []string {
+	var others []string
+	for _, p := range paths {
+		if p != path {
+			others = append(others, p)
+		}
+	}
+	return others
+}
 
-	return err
+// readFile returns the content of a file, without its trailing newline
+func readFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSuffix(string(b), "\n")
+	return strings.TrimSuffix(value, "\r"), nil
 }
 
 // MustProcess is the same as Process but panics if an error occurs
-func MustProcess(prefix string, spec interface{}) {
-	if err := Process(prefix, spec); err != nil {
+func MustProcess(prefix string, spec interface{}, opts ...Option) {
+	if err := Process(prefix, spec, opts...); err != nil {
 		panic(err)
 	}
 }
 
-func processField(value string, field reflect.Value) error {
+func processField(value string, field reflect.Value, seps separators) error {
 	typ := field.Type()
 
 	decoder := decoderFrom(field)
@@ -282,6 +647,15 @@ func processField(value string, field reflect.Value) error {
 		return b.UnmarshalBinary([]byte(value))
 	}
 
+	if typ == locationPtrType {
+		loc, err := time.LoadLocation(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(loc))
+		return nil
+	}
+
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
 		if field.IsNil() {
@@ -300,7 +674,7 @@ func processField(value string, field reflect.Value) error {
 		)
 		if field.Kind() == reflect.Int64 && typ.PkgPath() == "time" && typ.Name() == "Duration" {
 			var d time.Duration
-			d, err = time.ParseDuration(value)
+			d, err = parseDuration(value)
 			val = int64(d)
 		} else {
 			val, err = strconv.ParseInt(value, 0, typ.Bits())
@@ -329,36 +703,36 @@ func processField(value string, field reflect.Value) error {
 		}
 		field.SetFloat(val)
 	case reflect.Slice:
-		vals := strings.Split(value, ",")
+		vals, err := splitList(value, seps)
+		if err != nil {
+			return err
+		}
 		sl := reflect.MakeSlice(typ, len(vals), len(vals))
 		for i, val := range vals {
-			err := processField(val, sl.Index(i))
+			err := processField(val, sl.Index(i), defaultSeparators)
 			if err != nil {
 				return err
 			}
 		}
 		field.Set(sl)
 	case reflect.Map:
+		pairs, err := splitMap(value, seps)
+		if err != nil {
+			return err
+		}
 		mp := reflect.MakeMap(typ)
-		if len(strings.TrimSpace(value)) != 0 {
-			pairs := strings.Split(value, ",")
-			for _, pair := range pairs {
-				kvpair := strings.Split(pair, ":")
-				if len(kvpair) != 2 {
-					return fmt.Errorf("invalid map item: %q", pair)
-				}
-				k := reflect.New(typ.Key()).Elem()
-				err := processField(kvpair[0], k)
-				if err != nil {
-					return err
-				}
-				v := reflect.New(typ.Elem()).Elem()
-				err = processField(kvpair[1], v)
-				if err != nil {
-					return err
-				}
-				mp.SetMapIndex(k, v)
+		for _, pair := range pairs {
+			k := reflect.New(typ.Key()).Elem()
+			err := processField(pair[0], k, defaultSeparators)
+			if err != nil {
+				return err
+			}
+			v := reflect.New(typ.Elem()).Elem()
+			err = processField(pair[1], v, defaultSeparators)
+			if err != nil {
+				return err
 			}
+			mp.SetMapIndex(k, v)
 		}
 		field.Set(mp)
 	}
@@ -5,9 +5,12 @@
 package envconfig
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 	"net/url"
@@ -260,9 +263,9 @@ func TestParseErrorUint(t *testing.T) {
 	os.Clearenv()
 	os.Setenv("ENV_CONFIG_TTL", "-30")
 	err := Process("env_config", &s)
-	v, ok := err.(*ParseError)
-	if !ok {
-		t.Errorf("expected ParseError, got %v", v)
+	var v *ParseError
+	if !errors.As(err, &v) {
+		t.Fatalf("expected ParseError, got %v", err)
 	}
 	if v.FieldName != "TTL" {
 		t.Errorf("expected %s, got %v", "TTL", v.FieldName)
@@ -277,9 +280,9 @@ func TestParseErrorSplitWords(t *testing.T) {
 	os.Clearenv()
 	os.Setenv("ENV_CONFIG_MULTI_WORD_VAR_WITH_AUTO_SPLIT", "shakespeare")
 	err := Process("env_config", &s)
-	v, ok := err.(*ParseError)
-	if !ok {
-		t.Errorf("expected ParseError, got %v", v)
+	var v *ParseError
+	if !errors.As(err, &v) {
+		t.Fatalf("expected ParseError, got %v", err)
 	}
 	if v.FieldName != "MultiWordVarWithAutoSplit" {
 		t.Errorf("expected %s, got %v", "", v.FieldName)
@@ -758,7 +761,7 @@ func TestCheckDisallowedMispelled(t *testing.T) {
 	os.Setenv("ENV_CONFIG_DEBUG", "true")
 	os.Setenv("ENV_CONFIG_ZEBUG", "false")
 	err := CheckDisallowed("env_config", &s)
-	if experr := "unknown environment variable ENV_CONFIG_ZEBUG"; err.Error() != experr {
+	if experr := "unknown environment variable ENV_CONFIG_ZEBUG: did you mean ENV_CONFIG_DEBUG?"; err.Error() != experr {
 		t.Errorf("expected %s, got %s", experr, err)
 	}
 }
@@ -840,6 +843,279 @@ func BenchmarkGatherInfo(b *testing.B) {
 	os.Setenv("ENV_CONFIG_MULTI_WORD_VAR_WITH_AUTO_SPLIT", "24")
 	for i := 0; i < b.N; i++ {
 		var s Specification
-		gatherInfo("env_config", &s)
+		gatherInfo("env_config", &s, newOptions(nil))
+	}
+}
+
+func TestProcessGathersErrors(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_DEBUG", "string")
+	os.Setenv("ENV_CONFIG_PORT", "string")
+	err := Process("env_config", &s)
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %T %v", err, err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %d: %v", len(errs), errs)
+	}
+	for i, field := range []string{"Debug", "Port"} {
+		v, ok := errs[i].(*ParseError)
+		if !ok {
+			t.Fatalf("expected ParseError, got %T %v", errs[i], errs[i])
+		}
+		if v.FieldName != field {
+			t.Errorf("expected %s, got %v", field, v.FieldName)
+		}
+		if v.Value != "string" {
+			t.Errorf("expected %s, got %v", "string", v.Value)
+		}
+	}
+	if _, ok := errs[2].(*RequiredError); !ok {
+		t.Errorf("expected RequiredError, got %T %v", errs[2], errs[2])
+	}
+
+	var v *ParseError
+	if !errors.As(err, &v) || v.FieldName != "Debug" {
+		t.Errorf("expected errors.As to find the Debug ParseError, got %v", v)
+	}
+}
+
+func TestRequiredError(t *testing.T) {
+	var s struct {
+		PubSubA struct {
+			Topic string `required:"true"`
+		}
+	}
+	err := ProcessWith(MapLookuper{}, "test", &s)
+
+	v, ok := err.(*RequiredError)
+	if !ok {
+		t.Fatalf("expected RequiredError, got %T %v", err, err)
+	}
+	if v.Key != "TEST_PUBSUBA_TOPIC" {
+		t.Errorf("expected %s, got %v", "TEST_PUBSUBA_TOPIC", v.Key)
+	}
+	if v.FieldName != "Topic" {
+		t.Errorf("expected %s, got %v", "Topic", v.FieldName)
+	}
+	if v.Path != "PubSubA.Topic" {
+		t.Errorf("expected %s, got %v", "PubSubA.Topic", v.Path)
+	}
+	if expected := []string{"PUBSUBA_TOPIC", "TOPIC"}; !reflect.DeepEqual(v.Alternatives, expected) {
+		t.Errorf("expected %v, got %v", expected, v.Alternatives)
+	}
+	if experr := "required key TEST_PUBSUBA_TOPIC missing value (also tried PUBSUBA_TOPIC, TOPIC)"; v.Error() != experr {
+		t.Errorf("expected %s, got %s", experr, v)
+	}
+}
+
+func writeTempFile(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "envconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestFileVariant(t *testing.T) {
+	path := writeTempFile(t, "s3cr3t\n")
+	defer os.Remove(path)
+
+	var s struct {
+		Password string `required:"true"`
+		User     string `default:"admin"`
+	}
+	l := MapLookuper{
+		"APP_PASSWORD_FILE": path,
+	}
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Password != "s3cr3t" {
+		t.Errorf("expected %q, got %q", "s3cr3t", s.Password)
+	}
+	if s.User != "admin" {
+		t.Errorf("expected %q, got %q", "admin", s.User)
+	}
+
+	// A variable takes precedence over its _FILE variant
+	l["APP_PASSWORD"] = "direct"
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Password != "direct" {
+		t.Errorf("expected %q, got %q", "direct", s.Password)
+	}
+
+	// ...and a _FILE variant over the alternative names
+	delete(l, "APP_PASSWORD")
+	l["PASSWORD"] = "alternative"
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Password != "s3cr3t" {
+		t.Errorf("expected %q, got %q", "s3cr3t", s.Password)
+	}
+}
+
+func TestFileVariantCollisions(t *testing.T) {
+	path := writeTempFile(t, "s3cr3t\n")
+	defer os.Remove(path)
+
+	// APP_LOG_FILE is the key of LogFile, not a file to read Log from
+	var s struct {
+		Log     string
+		LogFile string
+	}
+	l := MapLookuper{"APP_LOG_FILE": path}
+	if err := ProcessWith(l, "app", &s, WithSplitWords()); err != nil {
+		t.Fatal(err)
+	}
+	if s.Log != "" || s.LogFile != path {
+		t.Errorf("expected only LogFile to be set, got %+v", s)
+	}
+
+	// shortened fallback names have no _FILE variant
+	var app struct {
+		DB struct {
+			Cert string
+		}
+	}
+	if err := ProcessWith(MapLookuper{"CERT_FILE": path}, "app", &app); err != nil {
+		t.Fatal(err)
+	}
+	if app.DB.Cert != "" {
+		t.Errorf("expected Cert to be empty, got %q", app.DB.Cert)
+	}
+	if err := CheckDisallowedWith(MapLookuper{"APP_CERT_FILE": path}, "app", &app); err == nil {
+		t.Error("expected an error for APP_CERT_FILE")
+	}
+	if err := ProcessWith(MapLookuper{"APP_DB_CERT_FILE": path}, "app", &app); err != nil {
+		t.Fatal(err)
+	}
+	if app.DB.Cert != "s3cr3t" {
+		t.Errorf("expected %q, got %q", "s3cr3t", app.DB.Cert)
+	}
+}
+
+func TestCheckDisallowedFileVariant(t *testing.T) {
+	var s struct {
+		Password string
+		Key      string `file:"true"`
+	}
+	l := MapLookuper{
+		"APP_PASSWORD_FILE": "/run/secrets/password",
+		"APP_KEY":           "/run/secrets/key",
+	}
+	if err := CheckDisallowedWith(l, "app", &s); err != nil {
+		t.Error(err)
+	}
+
+	// _FILE variants are unknown when the variable holds a path already
+	l["APP_KEY_FILE"] = "/run/secrets/key"
+	if err := CheckDisallowedWith(l, "app", &s); err == nil {
+		t.Error("expected an error for APP_KEY_FILE")
+	}
+}
+
+func TestFileTag(t *testing.T) {
+	path := writeTempFile(t, "from file\r\n")
+	defer os.Remove(path)
+
+	var s struct {
+		Key     string `file:"true"`
+		Default string `file:"true"`
+	}
+	s2 := s
+	l := MapLookuper{
+		"APP_KEY":          path,
+		"APP_KEY_FILE":     "/does/not/exist",
+		"APP_DEFAULT_FILE": path,
+	}
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Key != "from file" {
+		t.Errorf("expected %q, got %q", "from file", s.Key)
+	}
+	// _FILE variants are not used when the variable holds a path already
+	if s.Default != "" {
+		t.Errorf("expected %q, got %q", "", s.Default)
+	}
+
+	err := ProcessWith(MapLookuper{"APP_KEY": "/does/not/exist"}, "app", &s2)
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	if v.KeyName != "APP_KEY" || v.Value != "/does/not/exist" {
+		t.Errorf("expected APP_KEY=/does/not/exist, got %s=%s", v.KeyName, v.Value)
+	}
+	if !os.IsNotExist(v.Err) {
+		t.Errorf("expected a not exist error, got %v", v.Err)
+	}
+}
+
+type strictSpec struct {
+	Topic   string `strict:"true" alias:"legacy_topic"`
+	Timeout string `envconfig:"max_timeout"`
+	PubSub  struct {
+		Topic string
+	} `strict:"true"`
+	Loose string `strict:"false"`
+}
+
+func TestStrict(t *testing.T) {
+	l := MapLookuper{
+		"TOPIC":       "unrelated",
+		"TIMEOUT":     "unrelated",
+		"MAX_TIMEOUT": "10s",
+		"LOOSE":       "loose",
+	}
+	var s strictSpec
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	expected := strictSpec{Timeout: "10s", Loose: "loose"}
+	if s != expected {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+
+	s = strictSpec{}
+	if err := ProcessWith(l, "app", &s, WithStrict()); err != nil {
+		t.Fatal(err)
+	}
+	expected = strictSpec{Loose: "loose"}
+	if s != expected {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+
+	l = MapLookuper{
+		"APP_TOPIC":        "topic",
+		"LEGACY_TOPIC":     "legacy",
+		"APP_PUBSUB_TOPIC": "pubsub",
+	}
+	s = strictSpec{}
+	if err := ProcessWith(l, "app", &s, WithStrict()); err != nil {
+		t.Fatal(err)
+	}
+	if s.Topic != "topic" || s.PubSub.Topic != "pubsub" {
+		t.Errorf("unexpected %+v", s)
+	}
+
+	delete(l, "APP_TOPIC")
+	s = strictSpec{}
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Topic != "legacy" {
+		t.Errorf("expected %q, got %q", "legacy", s.Topic)
 	}
 }
@@ -0,0 +1,166 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors gathers every error found while processing a specification,
+// such as ParseError values and missing required keys.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the gathered errors.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Is reports whether any of the gathered errors matches target.
+// It lets errors.Is look into Errors with Go versions that do not
+// support multiple wrapped errors.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the gathered errors that matches target.
+// It lets errors.As look into Errors with Go versions that do not
+// support multiple wrapped errors.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// A RequiredError occurs when no value was found for a required field, neither
+// under its key nor under any of its alternative keys.
+type RequiredError struct {
+	Key          string
+	FieldName    string
+	Path         string
+	Alternatives []string
+	// Condition is set for fields tagged with required_if or required_with,
+	// such as "if AUTH_MODE=oidc" or "with TLS_KEY".
+	Condition string
+}
+
+func (e *RequiredError) Error() string {
+	msg := fmt.Sprintf("required key %s missing value", e.Key)
+	if e.Condition != "" {
+		msg = fmt.Sprintf("key %s required %s missing value", e.Key, e.Condition)
+	}
+	if len(e.Alternatives) == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s (also tried %s)", msg, strings.Join(e.Alternatives, ", "))
+}
+
+// A GroupError occurs when the fields of an exclusive group, tagged with the
+// same group and `exclusive:"true"`, do not have exactly one value set.
+type GroupError struct {
+	Group string
+	Keys  []string
+	// Set lists the keys that were set.
+	Set []string
+}
+
+func (e *GroupError) Error() string {
+	got := "none"
+	if len(e.Set) > 0 {
+		got = strings.Join(e.Set, ", ")
+	}
+	return fmt.Sprintf("exactly one of %s must be set for group %s, got %s", strings.Join(e.Keys, ", "), e.Group, got)
+}
+
+// A ValidationError occurs when the value of a field does not satisfy one of
+// its validation tags (notempty, min, max, oneof or pattern).
+type ValidationError struct {
+	KeyName   string
+	FieldName string
+	Rule      string
+	Value     string
+	Err       error
+
+	// secret is set for fields tagged with `secret:"true"`, whose value is
+	// masked in the error message
+	secret bool
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("envconfig.Process: validating %[1]s for %[2]s: '%[3]s' %[4]s", e.KeyName, e.FieldName, redact(e.secret, e.Value), e.Err)
+}
+
+// A StructError occurs when the Validate method of the specification, or of
+// one of its nested structs, returns an error.
+type StructError struct {
+	// Path is the path of the struct in the specification, empty for the
+	// specification itself.
+	Path string
+	// Prefix is the prefix of the keys of the struct.
+	Prefix string
+	Err    error
+}
+
+func (e *StructError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "specification"
+	}
+	if e.Prefix == "" {
+		return fmt.Sprintf("envconfig.Process: validating %s: %s", path, e.Err)
+	}
+	return fmt.Sprintf("envconfig.Process: validating %s (%s): %s", path, e.Prefix, e.Err)
+}
+
+// Unwrap returns the error returned by Validate.
+func (e *StructError) Unwrap() error {
+	return e.Err
+}
+
+// An UnknownVariableError is reported by CheckDisallowed for a variable with
+// the prefix that is not read by the specification.
+type UnknownVariableError struct {
+	Key string
+	// Suggestion is the closest key or alias of the specification, if any is
+	// close enough to be a likely misspelling.
+	Suggestion string
+}
+
+func (e *UnknownVariableError) Error() string {
+	msg := "unknown environment variable " + e.Key
+	if e.Suggestion != "" {
+		msg += ": did you mean " + e.Suggestion + "?"
+	}
+	return msg
+}
+
+// errorOrNil returns nil if errs is empty, its only error if it holds a single
+// one, and errs otherwise.
+func errorOrNil(errs Errors) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errs
+}
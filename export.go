@@ -0,0 +1,189 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encoder is the counterpart of Decoder, used by Export to render a value that
+// Decode can read back.
+type Encoder interface {
+	Encode() (string, error)
+}
+
+// Export renders a specification as KEY=value strings, in the form of
+// os.Environ, that Process reads back into an identical specification.
+//
+// Values are encoded with the first available of: an Encode method, a String
+// method for types implementing Decoder or Setter, a MarshalText method, a
+// MarshalBinary method, or the counterpart of the parsing done by Process for
+// the kind of the field. Nil pointers, empty slices and nil maps are omitted,
+// as well as fields tagged with `file:"true"`, which hold a path rather than
+// the value.
+func Export(prefix string, spec interface{}, opts ...Option) ([]string, error) {
+	m, keys, err := marshal(prefix, spec, newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	env := make([]string, len(keys))
+	for i, key := range keys {
+		env[i] = key + "=" + m[key]
+	}
+	return env, nil
+}
+
+// Marshal is the same as Export, but returns a MapLookuper, that can be read
+// back with ProcessWith.
+func Marshal(prefix string, spec interface{}, opts ...Option) (MapLookuper, error) {
+	m, _, err := marshal(prefix, spec, newOptions(opts))
+	return m, err
+}
+
+// marshal encodes the fields of spec, also returning the keys in field order.
+// spec is left untouched.
+func marshal(prefix string, spec interface{}, o *options) (MapLookuper, []string, error) {
+	o.readOnly = true
+	infos, err := gatherInfo(prefix, spec, o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := MapLookuper{}
+	var keys []string
+	for _, info := range infos {
+		if isTrue(info.Tags.Get("file")) {
+			continue
+		}
+		value, ok, err := info.encode()
+		if err != nil {
+			return nil, nil, fmt.Errorf("envconfig.Export: encoding %s: %v", info.Name, err)
+		}
+		if !ok {
+			continue
+		}
+		if _, found := m[info.Key]; !found {
+			keys = append(keys, info.Key)
+		}
+		m[info.Key] = value
+	}
+	return m, keys, nil
+}
+
+// encodeField is the reverse of processField. It returns false for values
+// that cannot be represented, such as nil pointers.
+func encodeField(field reflect.Value, seps separators) (string, bool, error) {
+	if !field.CanAddr() {
+		// interface lookups need an addressable value
+		v := reflect.New(field.Type()).Elem()
+		v.Set(field)
+		field = v
+	}
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr && field.IsNil() {
+		return "", false, nil
+	}
+
+	if e := encoderFrom(field); e != nil {
+		s, err := e.Encode()
+		return s, err == nil, err
+	}
+	if decoderFrom(field) != nil || setterFrom(field) != nil {
+		if s := stringerFrom(field); s != nil {
+			return s.String(), true, nil
+		}
+		return "", false, fmt.Errorf("%s implements neither Encoder nor fmt.Stringer", typ)
+	}
+	if t := textMarshaler(field); t != nil {
+		b, err := t.MarshalText()
+		return string(b), err == nil, err
+	}
+	if b := binaryMarshaler(field); b != nil {
+		data, err := b.MarshalBinary()
+		return string(data), err == nil, err
+	}
+
+	if typ == locationPtrType {
+		return field.Interface().(*time.Location).String(), true, nil
+	}
+	if typ.Kind() == reflect.Ptr {
+		return encodeField(field.Elem(), seps)
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return field.String(), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if typ.Kind() == reflect.Int64 && typ.PkgPath() == "time" && typ.Name() == "Duration" {
+			return time.Duration(field.Int()).String(), true, nil
+		}
+		return strconv.FormatInt(field.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'g', -1, typ.Bits()), true, nil
+	case reflect.Slice:
+		if field.Len() == 0 {
+			return "", false, nil
+		}
+		vals := make([]string, field.Len())
+		for i := range vals {
+			val, _, err := encodeField(field.Index(i), defaultSeparators)
+			if err != nil {
+				return "", false, err
+			}
+			vals[i] = quote(val, seps.list)
+		}
+		return strings.Join(vals, seps.list), true, nil
+	case reflect.Map:
+		if field.IsNil() {
+			return "", false, nil
+		}
+		pairs := make([]string, 0, field.Len())
+		for _, k := range field.MapKeys() {
+			key, _, err := encodeField(k, defaultSeparators)
+			if err != nil {
+				return "", false, err
+			}
+			val, _, err := encodeField(field.MapIndex(k), defaultSeparators)
+			if err != nil {
+				return "", false, err
+			}
+			pairs = append(pairs, quote(key, seps.list, seps.kv)+seps.kv+quote(val, seps.list))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, seps.list), true, nil
+	}
+
+	return "", false, fmt.Errorf("unsupported type %s", typ)
+}
+
+func encoderFrom(field reflect.Value) (e Encoder) {
+	interfaceFrom(field, func(v interface{}, ok *bool) { e, *ok = v.(Encoder) })
+	return e
+}
+
+func stringerFrom(field reflect.Value) (s fmt.Stringer) {
+	interfaceFrom(field, func(v interface{}, ok *bool) { s, *ok = v.(fmt.Stringer) })
+	return s
+}
+
+func textMarshaler(field reflect.Value) (t encoding.TextMarshaler) {
+	interfaceFrom(field, func(v interface{}, ok *bool) { t, *ok = v.(encoding.TextMarshaler) })
+	return t
+}
+
+func binaryMarshaler(field reflect.Value) (b encoding.BinaryMarshaler) {
+	interfaceFrom(field, func(v interface{}, ok *bool) { b, *ok = v.(encoding.BinaryMarshaler) })
+	return b
+}
@@ -0,0 +1,135 @@
+package envconfig
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type exportSpec struct {
+	Debug      bool
+	Port       int
+	Rate       float32
+	User       string
+	TTL        uint32
+	Timeout    time.Duration
+	AdminUsers []string
+	ColorCodes map[string]int
+	Datetime   time.Time
+	Bar        *bracketed
+	URL        *url.URL
+	Nested     struct {
+		Property string `envconfig:"inner"`
+	} `envconfig:"outer"`
+	SomePointer *string
+	Empty       []int
+	Path        string `file:"true"`
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	bar := bracketed("[bar]")
+	u, _ := url.Parse("https://github.com/objenious/envconfig")
+	s := exportSpec{
+		Debug:      true,
+		Port:       8080,
+		Rate:       0.5,
+		User:       "Kelsey",
+		TTL:        30,
+		Timeout:    2 * time.Minute,
+		AdminUsers: []string{"John", "Adam"},
+		ColorCodes: map[string]int{"red": 1, "blue": 3},
+		Datetime:   time.Date(2016, 8, 16, 18, 57, 5, 0, time.UTC),
+		Bar:        &bar,
+		URL:        u,
+		Path:       "/etc/passwd",
+	}
+	s.Nested.Property = "iamnested"
+
+	env, err := Export("env_config", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"ENV_CONFIG_DEBUG=true",
+		"ENV_CONFIG_PORT=8080",
+		"ENV_CONFIG_RATE=0.5",
+		"ENV_CONFIG_USER=Kelsey",
+		"ENV_CONFIG_TTL=30",
+		"ENV_CONFIG_TIMEOUT=2m0s",
+		"ENV_CONFIG_ADMINUSERS=John,Adam",
+		"ENV_CONFIG_COLORCODES=blue:3,red:1",
+		"ENV_CONFIG_DATETIME=2016-08-16T18:57:05Z",
+		"ENV_CONFIG_BAR=[bar]",
+		"ENV_CONFIG_URL=https://github.com/objenious/envconfig",
+		"ENV_CONFIG_OUTER_INNER=iamnested",
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(env, "\n"))
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	t.Parallel()
+	s := exportSpec{
+		Debug:      true,
+		Port:       -1,
+		Rate:       0.1,
+		TTL:        30,
+		Timeout:    90 * time.Second,
+		AdminUsers: []string{"John"},
+		ColorCodes: map[string]int{},
+		Datetime:   time.Date(2016, 8, 16, 18, 57, 5, 0, time.UTC),
+	}
+	s.Nested.Property = "iamnested"
+
+	m, err := Marshal("app", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got exportSpec
+	if err := ProcessWith(m, "app", &got); err != nil {
+		t.Fatal(err)
+	}
+	// ProcessWith allocates nil pointers to structs, Marshal leaves them alone
+	expected := s
+	expected.URL = &url.URL{}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+type tlsSpec struct {
+	Cert string
+}
+
+func TestExportReadOnly(t *testing.T) {
+	t.Parallel()
+	s := struct {
+		Port     int
+		TLS      *tlsSpec
+		Backends []*tlsSpec
+	}{Port: 80, Backends: []*tlsSpec{nil}}
+	env, err := Export("app", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"APP_PORT=80", "APP_BACKENDS_COUNT=1"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %q, got %q", expected, env)
+	}
+	if s.TLS != nil || s.Backends[0] != nil {
+		t.Errorf("expected nil pointers to be left alone, got %+v", s)
+	}
+}
+
+func TestExportUnsupported(t *testing.T) {
+	t.Parallel()
+	var s struct {
+		Value CustomURL
+	}
+	if _, err := Export("app", &s); err == nil {
+		t.Error("expected an error for a type without marshaler")
+	}
+}
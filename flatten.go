@@ -0,0 +1,135 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var keyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// Flatten converts a decoded configuration document into a MapLookuper,
+// using the keys Process looks for: nested keys are joined with underscores
+// and upper-cased, so that {"pubsuba": {"topic": "a"}} is stored under
+// PUBSUBA_TOPIC. Lists of values are joined with commas, and lists of objects
+// are stored under indexed keys (SERVERS_0_HOST, SERVERS_1_HOST...).
+//
+// doc must be a map, with string keys (map[string]interface{}) or with keys
+// of any type (map[interface{}]interface{}), as decoded by most YAML libraries.
+func Flatten(doc interface{}) (MapLookuper, error) {
+	m := MapLookuper{}
+	if !isObject(doc) {
+		return nil, fmt.Errorf("cannot flatten %T, expecting a map", doc)
+	}
+	if err := flatten(m, "", doc); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParseJSON reads a JSON object from r, and flattens it into a MapLookuper.
+func ParseJSON(r io.Reader) (MapLookuper, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return Flatten(doc)
+}
+
+// LoadJSON reads the JSON file at path, and flattens it into a MapLookuper.
+func LoadJSON(path string) (MapLookuper, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseJSON(f)
+}
+
+func isObject(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		return true
+	}
+	return false
+}
+
+func flatten(m MapLookuper, key string, v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if err := flatten(m, joinKey(key, k), val); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			if err := flatten(m, joinKey(key, fmt.Sprint(k)), val); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		for i, val := range v {
+			if err := flatten(m, joinKey(key, strconv.Itoa(i)), val); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for i, val := range v {
+			if isObject(val) {
+				if err := flatten(m, joinKey(key, strconv.Itoa(i)), val); err != nil {
+					return err
+				}
+				continue
+			}
+			s, err := flattenValue(key, val)
+			if err != nil {
+				return err
+			}
+			values = append(values, s)
+		}
+		if len(values) > 0 {
+			m[key] = strings.Join(values, ",")
+		}
+	case nil:
+	default:
+		s, err := flattenValue(key, v)
+		if err != nil {
+			return err
+		}
+		m[key] = s
+	}
+	return nil
+}
+
+func flattenValue(key string, v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		return string(b), err
+	case []interface{}:
+		return "", fmt.Errorf("cannot flatten nested list under %s", key)
+	}
+	return fmt.Sprint(v), nil
+}
+
+func joinKey(prefix, key string) string {
+	key = strings.ToUpper(keyReplacer.Replace(key))
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + key
+}
@@ -0,0 +1,74 @@
+package envconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseJSON(t *testing.T) {
+	t.Parallel()
+	doc := `{
+		"httpport": 8088,
+		"enablepprof": true,
+		"pubsuba": {"topic": "topicA", "max_extension": "11h"},
+		"pubsubb.topic": "topicB",
+		"admins": ["john", "adam"],
+		"servers": [{"host": "a"}, {"host": "b"}],
+		"nothing": null
+	}`
+	m, err := ParseJSON(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := MapLookuper{
+		"HTTPPORT":              "8088",
+		"ENABLEPPROF":           "true",
+		"PUBSUBA_TOPIC":         "topicA",
+		"PUBSUBA_MAX_EXTENSION": "11h",
+		"PUBSUBB_TOPIC":         "topicB",
+		"ADMINS":                "john,adam",
+		"SERVERS_0_HOST":        "a",
+		"SERVERS_1_HOST":        "b",
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %v, got %v", expected, m)
+	}
+}
+
+func TestFlattenNotAMap(t *testing.T) {
+	t.Parallel()
+	if _, err := Flatten([]interface{}{"a"}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestLayeredJSON(t *testing.T) {
+	t.Parallel()
+	file, err := ParseJSON(strings.NewReader(`{
+		"pubsuba": {"topic": "topicA", "max_extension": "11h"},
+		"pubsubb": {"topic": "topicB", "max_extension": "10m"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := MapLookuper{
+		"TEST_PUBSUBB_MAX_EXTENSION": "22m",
+		"HTTPPORT":                   "8088",
+	}
+
+	var cfg Config
+	if err := ProcessWith(MultiLookuper(env, file), "test", &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.HTTPPort != 8088 {
+		t.Errorf("expected %d, got %d", 8088, cfg.HTTPPort)
+	}
+	if cfg.PubSubA.Topic != "topicA" || cfg.PubSubA.MaxExtension != 11*time.Hour {
+		t.Errorf("expected topicA/11h, got %+v", cfg.PubSubA)
+	}
+	if cfg.PubSubB.Topic != "topicB" || cfg.PubSubB.MaxExtension != 22*time.Minute {
+		t.Errorf("expected topicB/22m, got %+v", cfg.PubSubB)
+	}
+}
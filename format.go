@@ -0,0 +1,84 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// isJSON reports whether a field is tagged with `format:"json"`
+func isJSON(tag reflect.StructTag) bool {
+	return tag.Get("format") == "json"
+}
+
+// assign parses value into the field, decoding JSON for fields tagged with
+// `format:"json"`, and times with the layout of their `layout` tag
+func (v varInfo) assign(value string) error {
+	if isJSON(v.Tags) {
+		return json.Unmarshal([]byte(value), v.Field.Addr().Interface())
+	}
+	if layout := v.layout(); layout != "" {
+		return assignTime(value, v.Field, layout)
+	}
+	return processField(value, v.Field, v.separators())
+}
+
+// encode is the reverse of assign
+func (v varInfo) encode() (string, bool, error) {
+	layout := v.layout()
+	if !isJSON(v.Tags) && layout == "" {
+		return encodeField(v.Field, v.separators())
+	}
+	if v.Field.Kind() == reflect.Ptr && v.Field.IsNil() {
+		return "", false, nil
+	}
+	if layout != "" {
+		return formatTime(reflect.Indirect(v.Field).Interface().(time.Time), layout), true, nil
+	}
+	b, err := json.Marshal(v.Field.Interface())
+	return string(b), err == nil, err
+}
+
+// render renders the value of the field
+func (v varInfo) render() string {
+	if isJSON(v.Tags) || v.layout() != "" {
+		if s, _, err := v.encode(); err == nil {
+			return s
+		}
+	}
+	return renderValue(v.Field, v.separators())
+}
+
+// jsonOffset returns the offset in the value of a JSON decoding error, and
+// false for other errors
+func jsonOffset(err error) (int64, bool) {
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) {
+		return serr.Offset, true
+	}
+	var terr *json.UnmarshalTypeError
+	if errors.As(err, &terr) {
+		return terr.Offset, true
+	}
+	return 0, false
+}
+
+// jsonDescription describes the type of a field tagged with `format:"json"`,
+// for usage output
+func jsonDescription(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return "JSON object"
+	case reflect.Slice, reflect.Array:
+		return "JSON array"
+	}
+	return "JSON value"
+}
@@ -0,0 +1,146 @@
+package envconfig
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type retryPolicy struct {
+	Attempts int      `json:"attempts"`
+	Backoff  []string `json:"backoff"`
+}
+
+type formatSpec struct {
+	Retry    retryPolicy            `format:"json"`
+	Limits   *retryPolicy           `format:"json"`
+	Labels   map[string]interface{} `format:"json" default:"{\"env\":\"dev\"}"`
+	Weights  []float64              `format:"json"`
+	Fallback retryPolicy
+}
+
+func TestFormatJSON(t *testing.T) {
+	t.Parallel()
+	l := MapLookuper{
+		"APP_RETRY":             `{"attempts":3,"backoff":["1s","5s"]}`,
+		"APP_WEIGHTS":           "[0.5, 1.5]",
+		"APP_FALLBACK_ATTEMPTS": "2",
+	}
+	var s formatSpec
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	expected := formatSpec{
+		Retry:    retryPolicy{Attempts: 3, Backoff: []string{"1s", "5s"}},
+		Labels:   map[string]interface{}{"env": "dev"},
+		Weights:  []float64{0.5, 1.5},
+		Fallback: retryPolicy{Attempts: 2},
+	}
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+
+	l["APP_LIMITS"] = `{"attempts":1}`
+	var s2 formatSpec
+	if err := ProcessWith(l, "app", &s2); err != nil {
+		t.Fatal(err)
+	}
+	if s2.Limits == nil || s2.Limits.Attempts != 1 {
+		t.Errorf("expected Limits to be decoded, got %+v", s2.Limits)
+	}
+
+	changes, err := Diff("app", &s, &s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "Limits" || changes[0].New != `{"attempts":1,"backoff":null}` {
+		t.Errorf("unexpected changes %v", changes)
+	}
+
+	m, err := Marshal("app", &s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["APP_RETRY"] != `{"attempts":3,"backoff":["1s","5s"]}` || m["APP_LIMITS"] != `{"attempts":1,"backoff":null}` {
+		t.Errorf("unexpected export %v", m)
+	}
+	var s3 formatSpec
+	if err := ProcessWith(m, "app", &s3); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s3, s2) {
+		t.Errorf("expected %+v, got %+v", s2, s3)
+	}
+}
+
+func TestFormatJSONError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value  string
+		offset int64
+	}{
+		{`{"attempts":3,}`, 15},
+		{`{"attempts":"3"}`, 15},
+	}
+	for _, test := range tests {
+		var s formatSpec
+		err := ProcessWith(MapLookuper{"APP_RETRY": test.value}, "app", &s)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s: expected a ParseError, got %v", test.value, err)
+			continue
+		}
+		if perr.Offset != test.offset {
+			t.Errorf("%s: expected offset %d, got %d", test.value, test.offset, perr.Offset)
+		}
+		if !strings.HasSuffix(err.Error(), " at offset "+strconv.FormatInt(test.offset, 10)) {
+			t.Errorf("%s: expected the offset in %q", test.value, err)
+		}
+	}
+
+	var s formatSpec
+	err := ProcessWith(MapLookuper{"APP_FALLBACK_ATTEMPTS": "x"}, "app", &s)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Offset != 0 || strings.Contains(err.Error(), "offset") {
+		t.Errorf("expected a ParseError without offset, got %v", err)
+	}
+
+	perr = &ParseError{KeyName: "APP_RETRY", FieldName: "Retry", TypeName: "int", Value: "x", Err: errors.New("bad")}
+	if strings.Contains(perr.Error(), "offset") {
+		t.Errorf("expected no offset in %q", perr)
+	}
+}
+
+func TestFormatJSONUsage(t *testing.T) {
+	t.Parallel()
+	var s formatSpec
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{.Key}} {{usage_type .}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"APP_RETRY JSON object",
+		"APP_LIMITS JSON object",
+		"APP_LABELS JSON object",
+		"APP_WEIGHTS JSON array",
+		"APP_FALLBACK_ATTEMPTS Integer",
+		"APP_FALLBACK_BACKOFF Comma-separated list of String",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestFormatJSONLint(t *testing.T) {
+	t.Parallel()
+	var s struct {
+		Retry retryPolicy `format:"json" default:"{attempts: 3}"`
+	}
+	issues := Lint("app", &s)
+	if len(issues) != 1 || issues[0].Kind != IssueInvalidTag {
+		t.Errorf("expected an invalid default, got %v", issues)
+	}
+}
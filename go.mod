@@ -1,5 +1,14 @@
 module github.com/objenious/envconfig
 
-go 1.13
+go 1.18
 
-require github.com/stretchr/testify v1.4.0
+require (
+	github.com/BurntSushi/toml v1.2.0
+	github.com/stretchr/testify v1.4.0
+	gopkg.in/yaml.v2 v2.2.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
@@ -0,0 +1,336 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// indexPlaceholder stands for the index of the elements of slices of
+	// structs in usage output
+	indexPlaceholder = "<N>"
+	// namePlaceholder stands for the names of the elements of maps of structs
+	// in usage output
+	namePlaceholder = "<NAME>"
+	// maxElements is the largest number of elements of a slice of structs
+	maxElements = 10000
+)
+
+var intPtrType = reflect.TypeOf((*int)(nil))
+
+// isIndexed reports whether a field is a slice of structs, or of pointers to
+// structs, read from indexed keys such as APP_BACKENDS_0_HOST. Slices with
+// their own decoding, or whose elements have one, are read from a single
+// comma separated value instead.
+func isIndexed(field reflect.Value) bool {
+	if field.Kind() != reflect.Slice || decodable(field) {
+		return false
+	}
+	typ := field.Type().Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && !decodable(reflect.New(typ).Elem())
+}
+
+// isKeyed reports whether a field is a map of structs, or of pointers to
+// structs, with string keys, read from keys holding the name of each element
+// such as APP_DB_PRIMARY_HOST.
+func isKeyed(field reflect.Value) bool {
+	if field.Kind() != reflect.Map || field.Type().Key().Kind() != reflect.String || decodable(field) {
+		return false
+	}
+	typ := field.Type().Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && !decodable(reflect.New(typ).Elem())
+}
+
+// decodable reports whether a value has its own decoding
+func decodable(field reflect.Value) bool {
+	return decoderFrom(field) != nil || setterFrom(field) != nil || textUnmarshaler(field) != nil || binaryUnmarshaler(field) != nil
+}
+
+// gatherSlice gathers information about the elements of a slice of structs.
+// Their keys are those of the fields of the element struct, prefixed with the
+// key of the slice and the index of the element. They are always strict, as
+// the fallback names would be shared by every element.
+//
+// The number of elements is read from the lookuper of the options, if any,
+// resizing the slice. It is given by the KEY_COUNT variable, or else by the
+// first index for which no key is set, up to maxElements. An invalid KEY_COUNT
+// leaves the slice empty, and is reported when processing it along with the
+// other errors. Without a lookuper, the elements of
+// the slice are gathered, or a single element with a placeholder index when
+// the options are set for usage output.
+//
+// KEY_COUNT is gathered first, as a field holding the number of elements. It
+// takes over the desc and required tags of the slice, a required slice needing
+// at least one element: KEY_COUNT is then required unless elements are found,
+// and must be at least 1.
+func gatherSlice(info varInfo, slice reflect.Value, o *options) ([]varInfo, []structInfo, error) {
+	count := varInfo{
+		Name:  info.Name,
+		Path:  info.Path,
+		Key:   info.Key + "_COUNT",
+		Alt:   []string{info.Key + "_COUNT"},
+		Field: reflect.New(intPtrType).Elem(),
+	}
+	desc := info.Tags.Get("desc")
+	if desc == "" {
+		desc = "number of elements of " + info.Name
+	}
+	count.Tags = reflect.StructTag(`desc:` + strconv.Quote(desc))
+	required := isTrue(info.Tags.Get("required"))
+	if required {
+		count.Tags += ` min:"1"`
+	}
+
+	elemOptions := *o
+	elemOptions.strict = true
+
+	var indexes []string
+	switch {
+	case o.usage:
+		indexes = []string{indexPlaceholder}
+		slice = reflect.New(slice.Type()).Elem()
+		slice.Set(reflect.MakeSlice(slice.Type(), 1, 1))
+	case o.lookuper != nil:
+		n, err := countElements(o.lookuper, info, &count, slice.Type(), &elemOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		if n > 0 {
+			resized := reflect.MakeSlice(slice.Type(), n, n)
+			reflect.Copy(resized, slice)
+			slice.Set(resized)
+		}
+		indexes = makeIndexes(n)
+		// elements found without KEY_COUNT satisfy the requirement
+		required = required && n == 0
+	default:
+		indexes = makeIndexes(slice.Len())
+		if slice.Len() > 0 {
+			n := slice.Len()
+			count.Field.Set(reflect.ValueOf(&n))
+		}
+	}
+
+	if required {
+		count.Tags += ` required:"true"`
+	}
+
+	infos := []varInfo{count}
+	var structs []structInfo
+	for i, index := range indexes {
+		elemInfos, elemStructs, err := gatherElement(info, index, slice.Index(i), &elemOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		infos = append(infos, elemInfos...)
+		structs = append(structs, elemStructs...)
+	}
+	return infos, structs, nil
+}
+
+// gatherElement gathers information about an element of a slice of structs
+func gatherElement(info varInfo, index string, elem reflect.Value, o *options) ([]varInfo, []structInfo, error) {
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() && o.readOnly {
+			return nil, nil, nil
+		}
+		if elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+		elem = elem.Elem()
+	}
+	infos, structs, err := gatherStruct(info.Key+"_"+index, elem.Addr().Interface(), o)
+	if err != nil {
+		return nil, nil, err
+	}
+	path := info.Path + "[" + index + "]"
+	for i := range infos {
+		infos[i].Path = path + "." + infos[i].Path
+	}
+	for i := range structs {
+		structs[i].Path = joinPath(path, structs[i].Path)
+	}
+	return infos, structs, nil
+}
+
+// countElements returns the number of elements of a slice of structs set in
+// l: the value of its count variable, or the first index for which no key is
+// set. An invalid count is set as the error of the count variable.
+func countElements(l Lookuper, info varInfo, count *varInfo, typ reflect.Type, o *options) (int, error) {
+	if value, ok := l.Lookup(count.Key); ok {
+		n, err := strconv.Atoi(value)
+		switch {
+		case err != nil:
+		case n < 0:
+			err = errors.New("negative count")
+		case n > maxElements:
+			err = fmt.Errorf("count exceeds %d", maxElements)
+		}
+		if err != nil {
+			count.Err = count.parseError(count.Key, value, err)
+			return 0, nil
+		}
+		return n, nil
+	}
+
+	for n := 0; n < maxElements; n++ {
+		elem := reflect.MakeSlice(typ, 1, 1).Index(0)
+		infos, _, err := gatherElement(info, strconv.Itoa(n), elem, o)
+		if err != nil {
+			return 0, err
+		}
+		if !anySet(l, infos) {
+			return n, nil
+		}
+	}
+	return maxElements, nil
+}
+
+// anySet reports whether a value is set in l for one of the fields
+func anySet(l Lookuper, infos []varInfo) bool {
+	for _, info := range infos {
+		// aliases are left out, as they are shared by every element
+		if _, ok := l.Lookup(info.Key); ok {
+			return true
+		}
+		if _, ok := l.Lookup(info.Key + "_FILE"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func makeIndexes(n int) []string {
+	indexes := make([]string, n)
+	for i := range indexes {
+		indexes[i] = strconv.Itoa(i)
+	}
+	return indexes
+}
+
+// gatherMap gathers information about the elements of a map of structs.
+// Their keys are those of the fields of the element struct, prefixed with the
+// key of the map and the name of the element. As for slices of structs, they
+// are always strict.
+//
+// The names are discovered by listing the keys of the lookuper of the options,
+// if any, which must then implement Environer. Without a lookuper, the
+// elements of the map are gathered, or a single element with a placeholder
+// name when the options are set for usage output.
+//
+// As map elements cannot be assigned in place, the values of maps of structs
+// (rather than of pointers) are gathered from copies, that the returned
+// structs store back into the map once processed.
+func gatherMap(info varInfo, m reflect.Value, o *options) ([]varInfo, []structInfo, error) {
+	elemOptions := *o
+	elemOptions.strict = true
+
+	var names []string
+	switch {
+	case o.usage:
+		names = []string{namePlaceholder}
+		m = reflect.New(m.Type()).Elem()
+	case o.lookuper != nil:
+		environer, ok := o.lookuper.(Environer)
+		if !ok {
+			return nil, nil, ErrNotEnumerable
+		}
+		var err error
+		names, err = discoverNames(environer, info, m.Type(), &elemOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(names) > 0 && m.IsNil() {
+			m.Set(reflect.MakeMap(m.Type()))
+		}
+	default:
+		for _, key := range m.MapKeys() {
+			names = append(names, key.String())
+		}
+		sort.Strings(names)
+	}
+
+	var infos []varInfo
+	var structs []structInfo
+	for _, name := range names {
+		key := reflect.ValueOf(name).Convert(m.Type().Key())
+		elem := reflect.New(m.Type().Elem()).Elem()
+		if existing := m.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		elemInfos, elemStructs, err := gatherElement(info, name, elem, &elemOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		if o.lookuper != nil && !o.usage {
+			if elem.Kind() == reflect.Ptr {
+				m.SetMapIndex(key, elem)
+			} else {
+				// the element itself is the last of its structs
+				elemStructs[len(elemStructs)-1].Map = m
+				elemStructs[len(elemStructs)-1].MapKey = key
+			}
+		}
+		infos = append(infos, elemInfos...)
+		structs = append(structs, elemStructs...)
+	}
+	return infos, structs, nil
+}
+
+// discoverNames lists the names of the elements of a map of structs for which
+// a key is set, in order. When a variable matches several fields, such as
+// APP_DB_A_REPLICA_HOST for the Host and ReplicaHost fields, the shortest name
+// is used.
+func discoverNames(environer Environer, info varInfo, typ reflect.Type, o *options) ([]string, error) {
+	usage := *o
+	usage.usage = true
+	elem := reflect.New(typ.Elem()).Elem()
+	infos, _, err := gatherElement(info, namePlaceholder, elem, &usage)
+	if err != nil {
+		return nil, err
+	}
+	var patterns []*regexp.Regexp
+	for _, info := range infos {
+		p := regexp.QuoteMeta(info.Key)
+		p = strings.Replace(p, namePlaceholder, "(.+?)", 1)
+		p = strings.Replace(p, namePlaceholder, ".+?", -1)
+		p = strings.Replace(p, indexPlaceholder, "[0-9]+", -1)
+		patterns = append(patterns, regexp.MustCompile("^"+p+"(?:_FILE)?$"))
+	}
+
+	found := make(map[string]struct{})
+	for _, env := range environer.Environ() {
+		key := strings.SplitN(env, "=", 2)[0]
+		name := ""
+		for _, p := range patterns {
+			if m := p.FindStringSubmatch(key); m != nil && (name == "" || len(m[1]) < len(name)) {
+				name = m[1]
+			}
+		}
+		if name != "" {
+			found[name] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
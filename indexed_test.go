@@ -0,0 +1,272 @@
+package envconfig
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type backend struct {
+	Host    string `required:"true"`
+	Port    int    `default:"80"`
+	Weights []int
+}
+
+type indexedSpec struct {
+	Backends []backend
+	Mirrors  []*backend
+	Times    []time.Time
+}
+
+func TestIndexedSlices(t *testing.T) {
+	t.Parallel()
+	l := MapLookuper{
+		"APP_BACKENDS_0_HOST":    "a",
+		"APP_BACKENDS_0_WEIGHTS": "1,2",
+		"APP_BACKENDS_1_HOST":    "b",
+		"APP_BACKENDS_1_PORT":    "8080",
+		"APP_BACKENDS_3_HOST":    "not contiguous",
+		"APP_MIRRORS_COUNT":      "1",
+		"APP_MIRRORS_0_HOST":     "m",
+		"HOST":                   "not a fallback",
+		"APP_TIMES":              "2016-08-16T18:57:05Z",
+	}
+	var s indexedSpec
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	expected := []backend{{Host: "a", Port: 80, Weights: []int{1, 2}}, {Host: "b", Port: 8080}}
+	if !reflect.DeepEqual(s.Backends, expected) {
+		t.Errorf("expected %+v, got %+v", expected, s.Backends)
+	}
+	if len(s.Mirrors) != 1 || !reflect.DeepEqual(*s.Mirrors[0], backend{Host: "m", Port: 80}) {
+		t.Errorf("unexpected mirrors %+v", s.Mirrors)
+	}
+	if len(s.Times) != 1 {
+		t.Errorf("expected a single time, got %v", s.Times)
+	}
+
+	env, err := Export("app", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedEnv := []string{
+		"APP_BACKENDS_COUNT=2",
+		"APP_BACKENDS_0_HOST=a",
+		"APP_BACKENDS_0_PORT=80",
+		"APP_BACKENDS_0_WEIGHTS=1,2",
+		"APP_BACKENDS_1_HOST=b",
+		"APP_BACKENDS_1_PORT=8080",
+		"APP_MIRRORS_COUNT=1",
+		"APP_MIRRORS_0_HOST=m",
+		"APP_MIRRORS_0_PORT=80",
+		"APP_TIMES=2016-08-16T18:57:05Z",
+	}
+	if !reflect.DeepEqual(env, expectedEnv) {
+		t.Errorf("expected %v, got %v", expectedEnv, env)
+	}
+}
+
+func TestIndexedSlicesCount(t *testing.T) {
+	t.Parallel()
+	var s indexedSpec
+	err := ProcessWith(MapLookuper{"APP_BACKENDS_COUNT": "2", "APP_BACKENDS_0_HOST": "a"}, "app", &s)
+	var rerr *RequiredError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected a RequiredError, got %v", err)
+	}
+	if rerr.Key != "APP_BACKENDS_1_HOST" || rerr.Path != "Backends[1].Host" {
+		t.Errorf("unexpected error %v for %s", rerr, rerr.Path)
+	}
+
+	err = ProcessWith(MapLookuper{"APP_BACKENDS_COUNT": "two"}, "app", &s)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.KeyName != "APP_BACKENDS_COUNT" {
+		t.Errorf("expected a ParseError for APP_BACKENDS_COUNT, got %v", err)
+	}
+
+	// invalid counts are reported along with the other errors
+	for _, value := range []string{"-1", "10001", "999999999999999"} {
+		var s2 indexedSpec
+		err = ProcessWith(MapLookuper{"APP_BACKENDS_COUNT": value, "APP_MIRRORS_0_PORT": "x"}, "app", &s2)
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Errorf("%s: expected 3 errors, got %v", value, err)
+			continue
+		}
+		if !errors.As(errs[0], &perr) || perr.KeyName != "APP_BACKENDS_COUNT" || perr.Value != value {
+			t.Errorf("%s: expected a ParseError for APP_BACKENDS_COUNT, got %v", value, errs[0])
+		}
+		if len(s2.Backends) != 0 {
+			t.Errorf("%s: expected no element, got %+v", value, s2.Backends)
+		}
+	}
+}
+
+func TestIndexedSlicesRequired(t *testing.T) {
+	t.Parallel()
+	type requiredSpec struct {
+		Backends []backend `required:"true" desc:"upstream servers"`
+	}
+	var s requiredSpec
+	err := ProcessWith(MapLookuper{}, "app", &s)
+	var rerr *RequiredError
+	if !errors.As(err, &rerr) || rerr.Key != "APP_BACKENDS_COUNT" || rerr.Path != "Backends" {
+		t.Errorf("expected a RequiredError for APP_BACKENDS_COUNT, got %v", err)
+	}
+
+	err = ProcessWith(MapLookuper{"APP_BACKENDS_COUNT": "0"}, "app", &s)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.KeyName != "APP_BACKENDS_COUNT" {
+		t.Errorf("expected a ValidationError for APP_BACKENDS_COUNT, got %v", err)
+	}
+
+	if err := ProcessWith(MapLookuper{"APP_BACKENDS_0_HOST": "a"}, "app", &s); err != nil {
+		t.Errorf("expected a single element to be enough, got %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{usage_key .}} {{usage_required .}} {{usage_description .}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	if first := strings.SplitN(buf.String(), "\n", 2)[0]; first != "APP_BACKENDS_COUNT true upstream servers" {
+		t.Errorf("unexpected usage %q", first)
+	}
+}
+
+func TestIndexedSlicesUsage(t *testing.T) {
+	t.Parallel()
+	var s indexedSpec
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{usage_key .}} {{usage_type .}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"APP_BACKENDS_COUNT Integer",
+		"APP_BACKENDS_<N>_HOST String",
+		"APP_BACKENDS_<N>_PORT Integer",
+		"APP_BACKENDS_<N>_WEIGHTS Comma-separated list of Integer",
+		"APP_MIRRORS_COUNT Integer",
+		"APP_MIRRORS_<N>_HOST String",
+		"APP_MIRRORS_<N>_PORT Integer",
+		"APP_MIRRORS_<N>_WEIGHTS Comma-separated list of Integer",
+		"APP_TIMES Comma-separated list of Time",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if s.Backends != nil {
+		t.Errorf("expected the specification to be left alone, got %+v", s.Backends)
+	}
+}
+
+func TestIndexedSlicesDiff(t *testing.T) {
+	t.Parallel()
+	prev := indexedSpec{Backends: []backend{{Host: "a"}, {Host: "b"}}}
+	next := indexedSpec{Backends: []backend{{Host: "c"}}}
+	changes, err := Diff("app", &prev, &next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{
+		{Key: "APP_BACKENDS_COUNT", Path: "Backends", Old: "2", New: "1"},
+		{Key: "APP_BACKENDS_0_HOST", Path: "Backends[0].Host", Old: "a", New: "c"},
+		{Key: "APP_BACKENDS_1_HOST", Path: "Backends[1].Host", Old: "b", New: ""},
+		{Key: "APP_BACKENDS_1_PORT", Path: "Backends[1].Port", Old: "0", New: ""},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, changes)
+	}
+}
+
+type database struct {
+	Host        string `required:"true"`
+	Port        int    `default:"5432"`
+	ReplicaHost string `split_words:"true"`
+}
+
+type keyedSpec struct {
+	DB     map[string]database
+	Queues map[string]*backend
+}
+
+func TestKeyedMaps(t *testing.T) {
+	t.Parallel()
+	l := MapLookuper{
+		"APP_DB_PRIMARY_HOST":                "db1",
+		"APP_DB_PRIMARY_PORT":                "5433",
+		"APP_DB_REPORTING_REPLICA_HOST":      "db2",
+		"APP_DB_REPORTING_REPLICA_HOST_FILE": "/dev/null",
+		"APP_DB_REPORTING_HOST":              "db3",
+		"APP_QUEUES_JOBS_HOST":               "q",
+		"APP_QUEUES_JOBS_WEIGHTS":            "1",
+		"APP_OTHER":                          "x",
+	}
+	s := keyedSpec{DB: map[string]database{"PRIMARY": {ReplicaHost: "kept"}}}
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]database{
+		"PRIMARY":   {Host: "db1", Port: 5433, ReplicaHost: "kept"},
+		"REPORTING": {Host: "db3", Port: 5432, ReplicaHost: "db2"},
+	}
+	if !reflect.DeepEqual(s.DB, expected) {
+		t.Errorf("expected %+v, got %+v", expected, s.DB)
+	}
+	if q := s.Queues["JOBS"]; q == nil || !reflect.DeepEqual(*q, backend{Host: "q", Port: 80, Weights: []int{1}}) {
+		t.Errorf("unexpected queues %+v", s.Queues)
+	}
+
+	err := CheckDisallowedWith(l, "app", &keyedSpec{})
+	var uerr *UnknownVariableError
+	if !errors.As(err, &uerr) || uerr.Key != "APP_OTHER" {
+		t.Errorf("expected APP_OTHER to be the only unknown variable, got %v", err)
+	}
+
+	err = ProcessWith(MapLookuper{"APP_DB_PRIMARY_PORT": "1"}, "app", &keyedSpec{})
+	var rerr *RequiredError
+	if !errors.As(err, &rerr) || rerr.Path != "DB[PRIMARY].Host" {
+		t.Errorf("expected a RequiredError for DB[PRIMARY].Host, got %v", err)
+	}
+
+	l2 := lookupFunc(func(key string) (string, bool) { return l.Lookup(key) })
+	if err := ProcessWith(l2, "app", &keyedSpec{}); err != ErrNotEnumerable {
+		t.Errorf("expected %v, got %v", ErrNotEnumerable, err)
+	}
+}
+
+func TestKeyedMapsUsageAndExport(t *testing.T) {
+	t.Parallel()
+	var s keyedSpec
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{usage_key .}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"APP_DB_<NAME>_HOST",
+		"APP_DB_<NAME>_PORT",
+		"APP_DB_<NAME>_REPLICA_HOST",
+		"APP_QUEUES_<NAME>_HOST",
+		"APP_QUEUES_<NAME>_PORT",
+		"APP_QUEUES_<NAME>_WEIGHTS",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	s.DB = map[string]database{"B": {Host: "b"}, "A": {Host: "a", Port: 1}}
+	env, err := Export("app", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedEnv := []string{
+		"APP_DB_A_HOST=a", "APP_DB_A_PORT=1", "APP_DB_A_REPLICA_HOST=",
+		"APP_DB_B_HOST=b", "APP_DB_B_PORT=0", "APP_DB_B_REPLICA_HOST=",
+	}
+	if !reflect.DeepEqual(env, expectedEnv) {
+		t.Errorf("expected %v, got %v", expectedEnv, env)
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IssueKind classifies the issues reported by Lint.
+type IssueKind string
+
+const (
+	// IssueInvalidSpecification is reported when the specification cannot be
+	// processed at all, such as a required_if tag naming an unknown field.
+	IssueInvalidSpecification IssueKind = "invalid specification"
+	// IssueDuplicateKey is reported when several fields have the same key.
+	IssueDuplicateKey IssueKind = "duplicate key"
+	// IssueSharedName is reported when a name looked up for a field is also
+	// the key, an alternative or an alias of another field.
+	IssueSharedName IssueKind = "shared name"
+	// IssueFileName is reported when the _FILE variant of the key or an alias
+	// of a field is a name looked up for another field, and is then not read
+	// as the path of a file.
+	IssueFileName IssueKind = "file name"
+	// IssueOSVariable is reported when a name looked up for a field is a
+	// variable usually set by the operating system, such as HOME.
+	IssueOSVariable IssueKind = "OS variable"
+	// IssueUnknownTag is reported for a struct tag that looks like a misspelled
+	// envconfig tag, such as requried.
+	IssueUnknownTag IssueKind = "unknown tag"
+	// IssueInvalidTag is reported for a tag whose value cannot be parsed, such
+	// as required:"yes" or a default that is not valid for the field type.
+	IssueInvalidTag IssueKind = "invalid tag"
+)
+
+// An Issue is a likely mistake in a specification, reported by Lint.
+type Issue struct {
+	Kind IssueKind
+	// Path is the path of the field in the specification, empty for issues
+	// about the whole specification.
+	Path    string
+	Key     string
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%s: %s", i.Kind, i.Message)
+	}
+	return fmt.Sprintf("%s (%s): %s: %s", i.Path, i.Key, i.Kind, i.Message)
+}
+
+// osVariables are set by the operating system or the shell, and should not be
+// read as configuration
+var osVariables = []string{"PATH", "HOME", "USER", "USERNAME", "LOGNAME", "SHELL", "PWD", "OLDPWD", "TERM", "LANG", "TMPDIR", "HOSTNAME"}
+
+// tagNames are the struct tags used by envconfig
+var tagNames = []string{
+	"envconfig", "default", "required", "split_words", "ignored", "desc", "file", "secret",
+	"min", "max", "oneof", "pattern", "notempty", "required_if", "required_with", "group",
+	"exclusive", "strict", "alias", "deprecated", "separator", "kv_separator",
+	"format", "layout",
+}
+
+// boolTags are the struct tags holding a boolean
+var boolTags = []string{"required", "split_words", "ignored", "file", "secret", "notempty", "exclusive", "strict"}
+
+// foreignTags are the struct tags of other packages that are close to
+// envconfig tags, yet not misspelled
+var foreignTags = []string{"ini", "env", "xml"}
+
+// Lint checks a specification for likely mistakes: several fields with the
+// same key, names looked up for several fields (such as the TOPIC fallback of
+// two nested structs with a Topic field), _FILE variants that are names of
+// other fields (such as LOG_FILE for the Log and LogFile fields), names that
+// are usually set by the operating system, misspelled tags and tags that cannot
+// be parsed.
+//
+// Issues are listed in field order.
+func Lint(prefix string, spec interface{}, opts ...Option) []Issue {
+	o := newOptions(opts)
+	o.usage = true
+	infos, err := gatherInfo(prefix, spec, o)
+	if err != nil {
+		return []Issue{{Kind: IssueInvalidSpecification, Message: err.Error()}}
+	}
+
+	owners := make(map[string][]varInfo)
+	for _, info := range infos {
+		for _, name := range append([]string{info.Key}, info.alternatives()...) {
+			owners[name] = append(owners[name], info)
+		}
+	}
+
+	paths := nameOwners(infos)
+
+	var issues []Issue
+	keys := make(map[string]string)
+	for _, info := range infos {
+		issue := func(kind IssueKind, format string, args ...interface{}) {
+			issues = append(issues, Issue{Kind: kind, Path: info.Path, Key: info.Key, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if first, found := keys[info.Key]; found {
+			issue(IssueDuplicateKey, "%s is also the key of %s", info.Key, first)
+		} else {
+			keys[info.Key] = info.Path
+		}
+		for _, name := range append([]string{info.Key}, info.alternatives()...) {
+			if others := otherKeys(owners[name], info.Key); name != info.Key && len(others) > 0 {
+				issue(IssueSharedName, "%s is also looked up for %s", name, strings.Join(others, ", "))
+			}
+			if contains(osVariables, name) {
+				issue(IssueOSVariable, "%s is usually set by the operating system", name)
+			}
+		}
+		for _, name := range info.fileCandidates() {
+			if others := otherPaths(paths[name], info.Path); len(others) > 0 {
+				issue(IssueFileName, "%s is not read as a file path, as it is also looked up for %s", name, strings.Join(others, ", "))
+			}
+		}
+
+		for _, tag := range tagKeys(info.Tags) {
+			if contains(tagNames, tag) || contains(foreignTags, tag) {
+				continue
+			}
+			if match := closestTag(tag); match != "" {
+				issue(IssueUnknownTag, "%s is not an envconfig tag, did you mean %s?", tag, match)
+			}
+		}
+		for _, err := range checkTags(info) {
+			issue(IssueInvalidTag, "%v", err)
+		}
+	}
+	return issues
+}
+
+// checkTags parses the values of the tags of a field
+func checkTags(info varInfo) []error {
+	var errs []error
+	for _, tag := range boolTags {
+		if value, ok := info.Tags.Lookup(tag); ok && value != "" {
+			if _, err := strconv.ParseBool(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s:%q is not a boolean", tag, value))
+			}
+		}
+	}
+
+	if seps := info.separators(); seps.list == seps.kv {
+		errs = append(errs, fmt.Errorf("separator and kv_separator are both %q", seps.list))
+	}
+
+	if layout, ok := info.Tags.Lookup("layout"); ok && info.layout() == "" {
+		errs = append(errs, fmt.Errorf("layout:%q only applies to time.Time fields", layout))
+	}
+
+	typ := info.Field.Type()
+	if def, ok := info.Tags.Lookup("default"); ok && def != "" && !isTrue(info.Tags.Get("file")) {
+		check := info
+		check.Field = reflect.New(typ).Elem()
+		if err := check.assign(def); err != nil {
+			errs = append(errs, fmt.Errorf("default:%q cannot be parsed: %v", def, err))
+		}
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	for _, tag := range []string{"min", "max"} {
+		if bound, ok := info.Tags.Lookup(tag); ok {
+			if _, err := compareBound(reflect.New(typ).Elem(), bound); err != nil {
+				errs = append(errs, fmt.Errorf("%s:%q %v", tag, bound, err))
+			}
+		}
+	}
+	if pattern, ok := info.Tags.Lookup("pattern"); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("pattern:%q cannot be compiled: %v", pattern, err))
+		}
+	}
+	return errs
+}
+
+// tagKeys lists the keys of a struct tag in the conventional format
+func tagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	s := string(tag)
+	for {
+		s = strings.TrimLeft(s, " ")
+		i := strings.Index(s, ":\"")
+		if i <= 0 {
+			return keys
+		}
+		keys = append(keys, s[:i])
+		s = s[i+2:]
+		// skip the quoted value
+		for i = 0; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(s) {
+			return keys
+		}
+		s = s[i+1:]
+	}
+}
+
+// closestTag returns the envconfig tag tag is likely a misspelling of, if any
+func closestTag(tag string) string {
+	for _, name := range tagNames {
+		max := 1
+		if len(name) >= 6 {
+			max = 2
+		}
+		if editDistance(tag, name) <= max {
+			return name
+		}
+	}
+	return ""
+}
+
+// editDistance counts the insertions, deletions, substitutions and
+// transpositions of adjacent characters turning a into b
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// otherKeys lists the paths of the fields whose key is not key, as fields
+// with the same key are reported as duplicates already
+func otherKeys(infos []varInfo, key string) []string {
+	var paths []string
+	for _, info := range infos {
+		if info.Key != key {
+			paths = append(paths, info.Path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,76 @@
+package envconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+type lintSpec struct {
+	Home     string
+	Port     int    `requried:"true" json:"port" ini:"port"`
+	Debug    bool   `required:"yes" defualt:"false"`
+	Timeout  int    `default:"1m" min:"1s"`
+	Name     string `pattern:"[" max:"ten"`
+	Alias    string `envconfig:"port"`
+	Inner    struct{ Name string }
+	Fallback string `alias:"inner_name"`
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+	var s lintSpec
+	issues := Lint("", &s)
+	expected := []Issue{
+		{Kind: IssueOSVariable, Path: "Home", Key: "HOME", Message: "HOME is usually set by the operating system"},
+		{Kind: IssueUnknownTag, Path: "Port", Key: "PORT", Message: "requried is not an envconfig tag, did you mean required?"},
+		{Kind: IssueUnknownTag, Path: "Debug", Key: "DEBUG", Message: "defualt is not an envconfig tag, did you mean default?"},
+		{Kind: IssueInvalidTag, Path: "Debug", Key: "DEBUG", Message: `required:"yes" is not a boolean`},
+		{Kind: IssueInvalidTag, Path: "Timeout", Key: "TIMEOUT", Message: `default:"1m" cannot be parsed: strconv.ParseInt: parsing "1m": invalid syntax`},
+		{Kind: IssueInvalidTag, Path: "Timeout", Key: "TIMEOUT", Message: `min:"1s" cannot be checked: invalid bound "1s"`},
+		{Kind: IssueInvalidTag, Path: "Name", Key: "NAME", Message: `max:"ten" cannot be checked: invalid length bound "ten"`},
+		{Kind: IssueInvalidTag, Path: "Name", Key: "NAME", Message: "pattern:\"[\" cannot be compiled: error parsing regexp: missing closing ]: `[`"},
+		{Kind: IssueDuplicateKey, Path: "Alias", Key: "PORT", Message: "PORT is also the key of Port"},
+		{Kind: IssueSharedName, Path: "Inner.Name", Key: "INNER_NAME", Message: "NAME is also looked up for Name"},
+		{Kind: IssueSharedName, Path: "Fallback", Key: "FALLBACK", Message: "INNER_NAME is also looked up for Inner.Name"},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, issues)
+	}
+}
+
+func TestLintFileName(t *testing.T) {
+	t.Parallel()
+	var s struct {
+		Log     string
+		LogFile string
+		Key     string `file:"true"`
+		KeyFile string
+	}
+	issues := Lint("app", &s, WithSplitWords(), WithStrict())
+	expected := []Issue{
+		{Kind: IssueFileName, Path: "Log", Key: "APP_LOG", Message: "APP_LOG_FILE is not read as a file path, as it is also looked up for LogFile"},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, issues)
+	}
+}
+
+func TestLintSharedFallback(t *testing.T) {
+	t.Parallel()
+	var c Config
+	issues := Lint("test", &c)
+	if len(issues) != 4 {
+		t.Fatalf("expected 4 issues, got %v", issues)
+	}
+	if got := issues[0].String(); got != "PubSubA.Topic (TEST_PUBSUBA_TOPIC): shared name: TOPIC is also looked up for PubSubB.Topic" {
+		t.Errorf("unexpected issue %q", got)
+	}
+	if issues := Lint("test", &c, WithStrict()); len(issues) != 0 {
+		t.Errorf("expected no issue in strict mode, got %v", issues)
+	}
+
+	issues = Lint("test", c)
+	if len(issues) != 1 || issues[0].Kind != IssueInvalidSpecification {
+		t.Errorf("expected an invalid specification, got %v", issues)
+	}
+}
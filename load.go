@@ -0,0 +1,44 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Load returns a specification of type T populated from the environment, as
+// Process does. T must be a struct type, or a pointer to one, in which case a
+// new struct is allocated.
+//
+//	cfg, err := envconfig.Load[Config]("myapp")
+func Load[T any](prefix string, opts ...Option) (T, error) {
+	return LoadWith[T](OsLookuper(), prefix, opts...)
+}
+
+// LoadWith is the same as Load, but reads the values found in l, as
+// ProcessWith does.
+func LoadWith[T any](l Lookuper, prefix string, opts ...Option) (T, error) {
+	var spec T
+	typ := reflect.TypeOf(&spec).Elem()
+	switch {
+	case typ.Kind() == reflect.Struct:
+		return spec, ProcessWith(l, prefix, &spec, opts...)
+	case typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct:
+		ptr := reflect.New(typ.Elem())
+		reflect.ValueOf(&spec).Elem().Set(ptr)
+		return spec, ProcessWith(l, prefix, ptr.Interface(), opts...)
+	}
+	return spec, fmt.Errorf("envconfig.Load: %s is not a struct type: %w", typ, ErrInvalidSpecification)
+}
+
+// MustLoad is the same as Load but panics if an error occurs
+func MustLoad[T any](prefix string, opts ...Option) T {
+	spec, err := Load[T](prefix, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
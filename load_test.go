@@ -0,0 +1,55 @@
+package envconfig
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	l := MapLookuper{"APP_PORT": "8000"}
+	var r Report
+	cfg, err := LoadWith[watchedSpec](l, "app", WithReport(&r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := watchedSpec{Port: 8000, Level: "info", Workers: 4}
+	if cfg != expected {
+		t.Errorf("expected %+v, got %+v", expected, cfg)
+	}
+	if len(r) != 3 || r[0].Key != "APP_PORT" {
+		t.Errorf("unexpected report %v", r)
+	}
+
+	ptr, err := LoadWith[*watchedSpec](l, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ptr == nil || *ptr != expected {
+		t.Errorf("expected %+v, got %+v", expected, ptr)
+	}
+
+	_, err = LoadWith[watchedSpec](MapLookuper{"APP_PORT": "none"}, "app")
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Errorf("expected a ParseError, got %v", err)
+	}
+}
+
+func TestLoadNotStruct(t *testing.T) {
+	_, err := Load[map[string]string]("app")
+	if !errors.Is(err, ErrInvalidSpecification) {
+		t.Fatalf("expected %v, got %v", ErrInvalidSpecification, err)
+	}
+	if expected := "envconfig.Load: map[string]string is not a struct type: specification must be a struct pointer"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err)
+	}
+}
+
+func TestMustLoad(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	MustLoad[int]("app")
+}
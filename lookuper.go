@@ -0,0 +1,155 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrNotEnumerable indicates that a Lookuper cannot list the keys it holds,
+// which is needed to detect unknown variables.
+var ErrNotEnumerable = errors.New("lookuper must implement Environer")
+
+// Lookuper is a source of configuration values.
+type Lookuper interface {
+	// Lookup returns the value stored under key, and whether it was set.
+	Lookup(key string) (string, bool)
+}
+
+// Environer is implemented by a Lookuper that can list its content,
+// in the "KEY=value" form returned by os.Environ.
+type Environer interface {
+	Environ() []string
+}
+
+// OsLookuper returns a Lookuper reading the process environment.
+func OsLookuper() Lookuper {
+	return osLookuper{}
+}
+
+type osLookuper struct{}
+
+func (osLookuper) Lookup(key string) (string, bool) {
+	// `os.Getenv` cannot differentiate between an explicitly set empty value
+	// and an unset value. `os.LookupEnv` is preferred to `syscall.Getenv`,
+	// but it is only available in go1.5 or newer. We're using Go build tags
+	// here to use os.LookupEnv for >=go1.5
+	return lookupEnv(key)
+}
+
+func (osLookuper) Environ() []string {
+	return os.Environ()
+}
+
+func (osLookuper) String() string {
+	return "env"
+}
+
+// MapLookuper is a Lookuper backed by a map, keys being looked up as is.
+type MapLookuper map[string]string
+
+// Lookup implements Lookuper
+func (m MapLookuper) Lookup(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// Environ implements Environer
+func (m MapLookuper) Environ() []string {
+	env := make([]string, 0, len(m))
+	for k, v := range m {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
+func (m MapLookuper) String() string {
+	return "map"
+}
+
+// Named returns a Lookuper reading from l, that is reported as name
+// in a Report. The returned Lookuper implements Environer if l does.
+func Named(name string, l Lookuper) Lookuper {
+	if e, ok := l.(Environer); ok {
+		return namedEnviron{named{name, l}, e}
+	}
+	return named{name, l}
+}
+
+type named struct {
+	name string
+	Lookuper
+}
+
+func (n named) String() string {
+	return n.name
+}
+
+type namedEnviron struct {
+	named
+	Environer
+}
+
+// MultiLookuper returns a Lookuper that asks each of the lookupers in turn,
+// returning the first value found. Earlier lookupers take precedence, so
+// layering the environment over a configuration file is done with
+// MultiLookuper(OsLookuper(), file). Each key is looked up in every layer before
+// moving on to the next alternative key.
+func MultiLookuper(lookupers ...Lookuper) Lookuper {
+	return multiLookuper(lookupers)
+}
+
+type multiLookuper []Lookuper
+
+func (m multiLookuper) Lookup(key string) (string, bool) {
+	for _, l := range m {
+		if v, ok := l.Lookup(key); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Environ lists the content of every lookuper implementing Environer,
+// keeping only the value that Lookup would return for each key.
+func (m multiLookuper) Environ() []string {
+	seen := make(map[string]struct{})
+	var env []string
+	for _, l := range m {
+		e, ok := l.(Environer)
+		if !ok {
+			continue
+		}
+		for _, kv := range e.Environ() {
+			k := strings.SplitN(kv, "=", 2)[0]
+			if _, found := seen[k]; found {
+				continue
+			}
+			seen[k] = struct{}{}
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// lookupSource looks key up in l, also returning the Lookuper it was found in,
+// which is one of the layers of l if it was built by MultiLookuper.
+func lookupSource(l Lookuper, key string) (string, Lookuper, bool) {
+	m, ok := l.(multiLookuper)
+	if !ok {
+		v, found := l.Lookup(key)
+		return v, l, found
+	}
+	for _, l := range m {
+		if v, source, found := lookupSource(l, key); found {
+			return v, source, true
+		}
+	}
+	return "", nil, false
+}
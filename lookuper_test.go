@@ -0,0 +1,104 @@
+package envconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProcessWithMapLookuper(t *testing.T) {
+	t.Parallel()
+	var s Specification
+	l := MapLookuper{
+		"ENV_CONFIG_PORT":        "8080",
+		"ENV_CONFIG_REQUIREDVAR": "foo",
+		"SERVICE_HOST":           "127.0.0.1",
+	}
+	if err := ProcessWith(l, "env_config", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Port != 8080 {
+		t.Errorf("expected %d, got %v", 8080, s.Port)
+	}
+	if s.RequiredVar != "foo" {
+		t.Errorf("expected %s, got %s", "foo", s.RequiredVar)
+	}
+	if s.NoPrefixWithAlt != "127.0.0.1" {
+		t.Errorf("expected %s, got %s", "127.0.0.1", s.NoPrefixWithAlt)
+	}
+	if s.DefaultVar != "foobar" {
+		t.Errorf("expected %s, got %s", "foobar", s.DefaultVar)
+	}
+}
+
+func TestMultiLookuper(t *testing.T) {
+	t.Parallel()
+	l := MultiLookuper(
+		MapLookuper{"A": "first"},
+		MapLookuper{"A": "second", "B": "second"},
+	)
+	if v, ok := l.Lookup("A"); !ok || v != "first" {
+		t.Errorf("expected %q, got %q (%v)", "first", v, ok)
+	}
+	if v, ok := l.Lookup("B"); !ok || v != "second" {
+		t.Errorf("expected %q, got %q (%v)", "second", v, ok)
+	}
+	if _, ok := l.Lookup("C"); ok {
+		t.Error("expected C to be unset")
+	}
+
+	env := l.(Environer).Environ()
+	if expected := []string{"A=first", "B=second"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %v, got %v", expected, env)
+	}
+}
+
+func TestCheckDisallowedWith(t *testing.T) {
+	t.Parallel()
+	var s Specification
+	l := MapLookuper{
+		"ENV_CONFIG_DEBUG": "true",
+		"ENV_CONFIG_ZEBUG": "false",
+	}
+	err := CheckDisallowedWith(l, "env_config", &s)
+	if experr := "unknown environment variable ENV_CONFIG_ZEBUG: did you mean ENV_CONFIG_DEBUG?"; err == nil || err.Error() != experr {
+		t.Errorf("expected %s, got %v", experr, err)
+	}
+}
+
+func TestCheckDisallowedWithAll(t *testing.T) {
+	t.Parallel()
+	var s Specification
+	l := MapLookuper{
+		"ENV_CONFIG_PROT":             "80",
+		"ENV_CONFIG_PORT_FILE":        "/run/secrets/port",
+		"ENV_CONFIG_SERVICE_HOST":     "localhost",
+		"ENV_CONFIG_PLUGIN_A":         "a",
+		"ENV_CONFIG_UNRELATED":        "x",
+		"ENV_CONFIG_REQUIREDVAR_FILE": "/run/secrets/required",
+	}
+	err := CheckDisallowedWith(l, "env_config", &s, WithAllowed("ENV_CONFIG_PLUGIN_*"))
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors, got %T %v", err, err)
+	}
+	expected := Errors{
+		&UnknownVariableError{Key: "ENV_CONFIG_PROT", Suggestion: "ENV_CONFIG_PORT"},
+		&UnknownVariableError{Key: "ENV_CONFIG_UNRELATED"},
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %v, got %v", expected, errs)
+	}
+}
+
+type lookupFunc func(string) (string, bool)
+
+func (f lookupFunc) Lookup(key string) (string, bool) { return f(key) }
+
+func TestCheckDisallowedWithNotEnumerable(t *testing.T) {
+	t.Parallel()
+	var s Specification
+	l := lookupFunc(func(string) (string, bool) { return "", false })
+	if err := CheckDisallowedWith(l, "env_config", &s); err != ErrNotEnumerable {
+		t.Errorf("expected %v, got %v", ErrNotEnumerable, err)
+	}
+}
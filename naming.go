@@ -0,0 +1,109 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameMapper converts a struct field name into the name of its variable,
+// before the prefix is added and the name is upper-cased. It is used for
+// fields tagged with `split_words:"true"`, or for every field with
+// WithSplitWords.
+type NameMapper func(fieldName string) string
+
+// DefaultAcronyms are the mixed case words kept whole by SplitWords.
+var DefaultAcronyms = []string{"GraphQL", "gRPC", "IPv4", "IPv6", "MySQL", "OAuth", "PProf", "PostgreSQL"}
+
+// SplitWords is the default NameMapper, separating the words of a camel cased
+// name with underscores. Runs of capitals are kept together (HTTPPort gives
+// HTTP_Port), digits are kept with the previous word (OAuth2ClientID gives
+// OAuth2_Client_ID), as are lower case letters following them (K8sNamespace
+// gives K8s_Namespace), and so are the DefaultAcronyms (EnablePProf gives
+// Enable_PProf).
+func SplitWords(name string) string {
+	return AcronymMapper(DefaultAcronyms...)(name)
+}
+
+// AcronymMapper returns a NameMapper splitting words as SplitWords does, but
+// with its own list of mixed case words to keep whole.
+func AcronymMapper(acronyms ...string) NameMapper {
+	return func(name string) string {
+		return strings.Join(splitWords(name, acronyms), "_")
+	}
+}
+
+// splitWords splits a camel cased name into words
+func splitWords(name string, acronyms []string) []string {
+	runes := []rune(name)
+	var words []string
+	for i := 0; i < len(runes); {
+		start := i
+		if n := matchAcronym(runes[i:], acronyms); n > 0 {
+			i += n
+		} else if unicode.IsUpper(runes[i]) {
+			i++
+			if i < len(runes) && unicode.IsUpper(runes[i]) {
+				// a run of capitals, the last one starting the next word
+				// if it is followed by a lower case letter, unless that is
+				// a plural (URLs)
+				for i < len(runes) && unicode.IsUpper(runes[i]) {
+					i++
+				}
+				switch {
+				case isPlural(runes, i):
+					i++
+				case i < len(runes) && unicode.IsLower(runes[i]):
+					i--
+				}
+			} else {
+				i = skipLower(runes, i)
+			}
+		} else if !unicode.IsDigit(runes[i]) {
+			i = skipLower(runes, i+1)
+		}
+		if i < len(runes) && unicode.IsDigit(runes[i]) {
+			// digits and the lower case letters following them end the
+			// word (K8sNamespace gives K8s_Namespace)
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || unicode.IsLower(runes[i])) {
+				i++
+			}
+		}
+		words = append(words, string(runes[start:i]))
+	}
+	return words
+}
+
+// isPlural reports whether runes[i] is a lone s ending a word
+func isPlural(runes []rune, i int) bool {
+	return i < len(runes) && runes[i] == 's' && (i+1 == len(runes) || !unicode.IsLower(runes[i+1]))
+}
+
+// skipLower returns the index of the first rune from i that is neither a lower
+// case letter nor a character that doesn't have a case
+func skipLower(runes []rune, i int) int {
+	for i < len(runes) && !unicode.IsUpper(runes[i]) && !unicode.IsDigit(runes[i]) {
+		i++
+	}
+	return i
+}
+
+// matchAcronym returns the length of the longest acronym runes starts with,
+// when it ends a word
+func matchAcronym(runes []rune, acronyms []string) int {
+	longest := 0
+	for _, a := range acronyms {
+		ar := []rune(a)
+		if len(ar) <= longest || len(ar) > len(runes) || string(runes[:len(ar)]) != a {
+			continue
+		}
+		if len(ar) < len(runes) && unicode.IsLower(runes[len(ar)]) {
+			continue
+		}
+		longest = len(ar)
+	}
+	return longest
+}
@@ -0,0 +1,94 @@
+package envconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"AutoSplitVar":   "Auto_Split_Var",
+		"HTTPPort":       "HTTP_Port",
+		"TLSKey":         "TLS_Key",
+		"OIDCIssuer":     "OIDC_Issuer",
+		"ClientID":       "Client_ID",
+		"EnablePProf":    "Enable_PProf",
+		"OAuth2ClientID": "OAuth2_Client_ID",
+		"GraphQLURL":     "GraphQL_URL",
+		"ServerIPv6":     "Server_IPv6",
+		"AllowedURLs":    "Allowed_URLs",
+		"V2Api":          "V2_Api",
+		"Var1":           "Var1",
+		"K8sNamespace":   "K8s_Namespace",
+		"Log4jPath":      "Log4j_Path",
+		"Http2xxCount":   "Http2xx_Count",
+		"lowercase":      "lowercase",
+	}
+	for name, expected := range tests {
+		if got := SplitWords(name); got != expected {
+			t.Errorf("SplitWords(%q): expected %q, got %q", name, expected, got)
+		}
+	}
+}
+
+func TestAcronymMapper(t *testing.T) {
+	t.Parallel()
+	m := AcronymMapper("MongoDB")
+	if got := m("MongoDBURI"); got != "MongoDB_URI" {
+		t.Errorf("expected %q, got %q", "MongoDB_URI", got)
+	}
+	if got := m("EnablePProf"); got != "Enable_P_Prof" {
+		t.Errorf("expected %q, got %q", "Enable_P_Prof", got)
+	}
+}
+
+type namingSpec struct {
+	EnablePProf    bool
+	OAuth2ClientID string
+	DebugPort      int    `split_words:"false"`
+	HTTPTimeout    string `envconfig:"timeout"`
+}
+
+func TestWithSplitWords(t *testing.T) {
+	t.Parallel()
+	l := MapLookuper{
+		"APP_ENABLE_PPROF":      "true",
+		"APP_OAUTH2_CLIENT_ID":  "client",
+		"APP_DEBUGPORT":         "6060",
+		"APP_TIMEOUT":           "5s",
+		"APP_OAUTH_2_CLIENT_ID": "wrong",
+	}
+	var s namingSpec
+	if err := ProcessWith(l, "app", &s, WithSplitWords()); err != nil {
+		t.Fatal(err)
+	}
+	expected := namingSpec{EnablePProf: true, OAuth2ClientID: "client", DebugPort: 6060, HTTPTimeout: "5s"}
+	if s != expected {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+}
+
+func TestWithNameMapper(t *testing.T) {
+	t.Parallel()
+	l := MapLookuper{
+		"APP_ENABLE-P-PROF": "true",
+		"APP_DEBUGPORT":     "6060",
+	}
+	var s namingSpec
+	m := func(name string) string { return strings.Join(splitWords(name, nil), "-") }
+	if err := ProcessWith(l, "app", &s, WithSplitWords(), WithNameMapper(m)); err != nil {
+		t.Fatal(err)
+	}
+	if !s.EnablePProf || s.DebugPort != 6060 {
+		t.Errorf("unexpected %+v", s)
+	}
+
+	keys, err := Export("app", &s, WithSplitWords(), WithNameMapper(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys[0] != "APP_ENABLE-P-PROF=true" {
+		t.Errorf("expected APP_ENABLE-P-PROF=true, got %q", keys[0])
+	}
+}
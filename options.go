@@ -0,0 +1,85 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import "path"
+
+// Option configures how a specification is processed.
+type Option func(*options)
+
+type options struct {
+	report      *Report
+	nameMapper  NameMapper
+	splitWords  bool
+	strict      bool
+	deprecation func(*DeprecationWarning)
+	allowlist   []string
+
+	// lookuper is set when gathering information to read values from it, to
+	// count the elements of slices of structs, and usage when gathering
+	// information for usage output
+	lookuper Lookuper
+	usage    bool
+	// readOnly is set when gathering information to render values, leaving
+	// out nil pointers to structs instead of allocating them
+	readOnly bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{nameMapper: SplitWords, deprecation: logDeprecation}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithReport fills r with the provenance of the value of each field, replacing
+// its previous content, so that r can be reused across calls.
+func WithReport(r *Report) Option {
+	return func(o *options) { o.report = r }
+}
+
+// WithNameMapper uses m instead of SplitWords to derive the variable names of
+// fields whose words are split.
+func WithNameMapper(m NameMapper) Option {
+	return func(o *options) { o.nameMapper = m }
+}
+
+// WithSplitWords splits the words of every field name, as if each field was
+// tagged with `split_words:"true"`. Fields tagged with `split_words:"false"`
+// are left alone.
+func WithSplitWords() Option {
+	return func(o *options) { o.splitWords = true }
+}
+
+// WithStrict looks up every field under its key and the names listed in its
+// alias tag only, as if each field was tagged with `strict:"true"`, instead of
+// falling back to the shorter names generated by dropping the prefix. Fields
+// tagged with `strict:"false"` keep the fallback.
+func WithStrict() Option {
+	return func(o *options) { o.strict = true }
+}
+
+// WithDeprecationHandler calls h for each value found under a deprecated name,
+// instead of logging it with the standard logger.
+func WithDeprecationHandler(h func(*DeprecationWarning)) Option {
+	return func(o *options) { o.deprecation = h }
+}
+
+// WithAllowed lets CheckDisallowed accept the variables matching one of the
+// patterns, in the syntax of path.Match, such as MYAPP_PLUGIN_*.
+func WithAllowed(patterns ...string) Option {
+	return func(o *options) { o.allowlist = append(o.allowlist, patterns...) }
+}
+
+// allowed tells whether key matches one of the patterns given with WithAllowed
+func (o *options) allowed(key string) bool {
+	for _, pattern := range o.allowlist {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// KeyDefault is the key reported for fields set to their default value
+	KeyDefault = "default"
+	// KeyUnset is the key reported for fields left untouched
+	KeyUnset = "unset"
+)
+
+// Report lists where the value of each field of a specification was found.
+type Report []FieldReport
+
+// FieldReport describes where the value of a field was found.
+type FieldReport struct {
+	// Path is the path of the field in the specification, such as PubSubA.Topic.
+	Path string `json:"path"`
+	// Key is the key the value was found under, KeyDefault or KeyUnset.
+	Key string `json:"key"`
+	// Source names the Lookuper the key was found in.
+	Source string `json:"source,omitempty"`
+	// Shadowed lists the alternative keys that were also set, but ignored.
+	Shadowed []string `json:"shadowed,omitempty"`
+}
+
+func (f FieldReport) String() string {
+	s := f.Path + ": " + f.Key
+	if f.Source != "" {
+		s += " (" + f.Source + ")"
+	}
+	if len(f.Shadowed) > 0 {
+		s += ", shadowing " + strings.Join(f.Shadowed, ", ")
+	}
+	return s
+}
+
+// String returns a line per field.
+func (r Report) String() string {
+	lines := make([]string, len(r))
+	for i, f := range r {
+		lines[i] = f.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// add appends the provenance of the value of info, doing nothing on a nil Report
+func (r *Report) add(info varInfo, m match, ok, hasDefault bool) {
+	if r == nil {
+		return
+	}
+	f := FieldReport{Path: info.Path, Key: m.key, Shadowed: m.shadowed}
+	switch {
+	case ok:
+		f.Source = sourceName(m.source)
+	case hasDefault:
+		f.Key = KeyDefault
+	default:
+		f.Key = KeyUnset
+	}
+	*r = append(*r, f)
+}
+
+// sourceName returns the name of a Lookuper, as returned by its String method
+func sourceName(l Lookuper) string {
+	if s, ok := l.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", l)
+}
@@ -0,0 +1,54 @@
+package envconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+	env := MapLookuper{
+		"PUBSUBA_TOPIC":         "topicA",
+		"TOPIC":                 "topicB",
+		"TEST_HTTPPORT":         "8080",
+		"HTTPPORT":              "8088",
+		"PUBSUBA_MAX_EXTENSION": "11h",
+	}
+	file := MapLookuper{
+		"TEST_PUBSUBA_MAX_EXTENSION": "22m",
+	}
+
+	var cfg Config
+	var r Report
+	l := MultiLookuper(env, Named("config.json", file))
+	if err := ProcessWith(l, "test", &cfg, WithReport(&r)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Report{
+		{Path: "HTTPConfig.LivenessCheckPath", Key: KeyUnset},
+		{Path: "HTTPConfig.ReadinessCheckPath", Key: KeyUnset},
+		{Path: "HTTPConfig.HTTPPort", Key: "TEST_HTTPPORT", Source: "map", Shadowed: []string{"HTTPPORT"}},
+		{Path: "HTTPConfig.EnablePProf", Key: KeyUnset},
+		{Path: "PubSubA.Topic", Key: "PUBSUBA_TOPIC", Source: "map", Shadowed: []string{"TOPIC"}},
+		{Path: "PubSubA.MaxExtension", Key: "TEST_PUBSUBA_MAX_EXTENSION", Source: "config.json", Shadowed: []string{"PUBSUBA_MAX_EXTENSION"}},
+		{Path: "PubSubB.Topic", Key: "TOPIC", Source: "map"},
+		{Path: "PubSubB.MaxExtension", Key: KeyDefault},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected\n%v\ngot\n%v", expected, r)
+	}
+
+	// a report is reset on each call
+	var cfg2 Config
+	if err := ProcessWith(l, "test", &cfg2, WithReport(&r)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected\n%v\ngot\n%v", expected, r)
+	}
+
+	if s, expected := r[4].String(), "PubSubA.Topic: PUBSUBA_TOPIC (map), shadowing TOPIC"; s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
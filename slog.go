@@ -0,0 +1,38 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package envconfig
+
+import "log/slog"
+
+// Redacted returns a slog.LogValuer rendering a processed specification as a
+// group with an attribute per key. As with Dump, the values of fields tagged
+// with `secret:"true"` are masked.
+func Redacted(prefix string, spec interface{}, opts ...Option) slog.LogValuer {
+	o := newOptions(opts)
+	o.readOnly = true
+	return redacted{prefix: prefix, spec: spec, opts: o}
+}
+
+type redacted struct {
+	prefix string
+	spec   interface{}
+	opts   *options
+}
+
+// LogValue implements slog.LogValuer
+func (r redacted) LogValue() slog.Value {
+	infos, err := gatherInfo(r.prefix, r.spec, r.opts)
+	if err != nil {
+		return slog.StringValue(err.Error())
+	}
+	attrs := make([]slog.Attr, len(infos))
+	for i, info := range infos {
+		attrs[i] = slog.String(info.Key, info.redactedValue())
+	}
+	return slog.GroupValue(attrs...)
+}
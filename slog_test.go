@@ -0,0 +1,29 @@
+//go:build go1.21
+// +build go1.21
+
+package envconfig
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestRedacted(t *testing.T) {
+	t.Parallel()
+	s := secretSpec{User: "admin", Password: "s3cr3t"}
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("loaded", "config", Redacted("app", &s))
+	expected := "level=INFO msg=loaded config.APP_USER=admin config.APP_PASSWORD=****** config.APP_TOKEN=\"\" config.APP_PORT=\"\" config.APP_TIMEOUT=0s\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf)
+	}
+}
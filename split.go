@@ -0,0 +1,150 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// separators split the values of slices and maps: elements and pairs are
+// separated by list, keys and values by kv.
+type separators struct {
+	list string
+	kv   string
+}
+
+// defaultSeparators are used unless a field is tagged with `separator` or
+// `kv_separator`, and always for the elements of slices and maps
+var defaultSeparators = separators{list: ",", kv: ":"}
+
+// separators returns the separators set by the tags of the field
+func (v varInfo) separators() separators {
+	seps := defaultSeparators
+	if sep := v.Tags.Get("separator"); sep != "" {
+		seps.list = sep
+	}
+	if sep := v.Tags.Get("kv_separator"); sep != "" {
+		seps.kv = sep
+	}
+	return seps
+}
+
+// separatorNames are used to describe the types of slices and maps
+var separatorNames = map[string]string{
+	",":  "Comma",
+	";":  "Semicolon",
+	":":  "Colon",
+	"|":  "Pipe",
+	" ":  "Space",
+	"\t": "Tab",
+	"\n": "Newline",
+}
+
+// describe returns the name of the list separator for usage output
+func (s separators) describe() string {
+	if name, found := separatorNames[s.list]; found {
+		return name + "-separated"
+	}
+	return fmt.Sprintf("%q-separated", s.list)
+}
+
+var errUnterminatedQuote = errors.New("unterminated quoted element")
+
+// splitList splits the elements of a slice. Elements can be quoted as in CSV:
+// within double quotes, separators are part of the element, and a double
+// quote is written twice.
+func splitList(value string, seps separators) ([]string, error) {
+	var elems []string
+	for {
+		elem, n, err := readElement(value, seps.list)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+		if n == len(value) {
+			return elems, nil
+		}
+		value = value[n+len(seps.list):]
+	}
+}
+
+// splitMap splits the pairs of a map, each key being separated from its value
+// by the first kv separator. Keys and values can be quoted as the elements of
+// slices.
+func splitMap(value string, seps separators) ([][2]string, error) {
+	if len(strings.TrimSpace(value)) == 0 {
+		return nil, nil
+	}
+	var pairs [][2]string
+	for {
+		item := value
+		if i := strings.Index(item, seps.list); i >= 0 {
+			item = item[:i]
+		}
+		key, n, err := readElement(value, seps.kv)
+		if err != nil {
+			return nil, err
+		}
+		if n == len(value) || (!strings.HasPrefix(value, `"`) && strings.Contains(key, seps.list)) {
+			return nil, fmt.Errorf("invalid map item: %q", item)
+		}
+		value = value[n+len(seps.kv):]
+
+		val, n, err := readElement(value, seps.list)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, [2]string{key, val})
+		if n == len(value) {
+			return pairs, nil
+		}
+		value = value[n+len(seps.list):]
+	}
+}
+
+// readElement reads an element up to the separator sep, unquoting it if it
+// starts with a double quote, and returns the number of bytes read
+func readElement(s, sep string) (string, int, error) {
+	if !strings.HasPrefix(s, `"`) {
+		if i := strings.Index(s, sep); i >= 0 {
+			return s[:i], i, nil
+		}
+		return s, len(s), nil
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != '"' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '"' {
+			b.WriteByte('"')
+			i++
+			continue
+		}
+		i++
+		if i < len(s) && !strings.HasPrefix(s[i:], sep) {
+			return "", 0, fmt.Errorf("unexpected %q after quoted element", s[i:])
+		}
+		return b.String(), i, nil
+	}
+	return "", 0, errUnterminatedQuote
+}
+
+// quote quotes an element if it contains one of the separators, or starts
+// with a double quote
+func quote(s string, seps ...string) string {
+	needed := strings.HasPrefix(s, `"`)
+	for _, sep := range seps {
+		needed = needed || strings.Contains(s, sep)
+	}
+	if !needed {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
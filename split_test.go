@@ -0,0 +1,119 @@
+package envconfig
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitList(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value    string
+		sep      string
+		expected []string
+	}{
+		{"a,b,c", ",", []string{"a", "b", "c"}},
+		{"", ",", []string{""}},
+		{"a,", ",", []string{"a", ""}},
+		{`"a,b",c`, ",", []string{"a,b", "c"}},
+		{`"say ""hi""",b`, ",", []string{`say "hi"`, "b"}},
+		{`a"b,c`, ",", []string{`a"b`, "c"}},
+		{"http://a:80;http://b:80", ";", []string{"http://a:80", "http://b:80"}},
+		{"a::b", "::", []string{"a", "b"}},
+	}
+	for _, test := range tests {
+		got, err := splitList(test.value, separators{list: test.sep, kv: ":"})
+		if err != nil {
+			t.Errorf("%q: %v", test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q: expected %q, got %q", test.value, test.expected, got)
+		}
+	}
+
+	for _, value := range []string{`"a`, `"a"b,c`} {
+		if _, err := splitList(value, defaultSeparators); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestSplitMap(t *testing.T) {
+	t.Parallel()
+	pairs, err := splitMap(`a:http://a:80,"b,c":"d,e",f:`, defaultSeparators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][2]string{{"a", "http://a:80"}, {"b,c", "d,e"}, {"f", ""}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("expected %q, got %q", expected, pairs)
+	}
+
+	for _, value := range []string{"a", "a,b:c", `"a:b`} {
+		if _, err := splitMap(value, defaultSeparators); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+type separatorSpec struct {
+	Upstreams []string          `separator:";"`
+	Routes    map[string]string `separator:";" kv_separator:"="`
+	Tags      []string
+	Weights   map[string][]int `separator:" "`
+}
+
+func TestSeparators(t *testing.T) {
+	t.Parallel()
+	l := MapLookuper{
+		"APP_UPSTREAMS": "http://a:80;http://b:80",
+		"APP_ROUTES":    "/api=http://a:80/v1?x=1;/=http://b:80",
+		"APP_TAGS":      `"a,b",c`,
+		"APP_WEIGHTS":   "a:1,2 b:3",
+	}
+	var s separatorSpec
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	expected := separatorSpec{
+		Upstreams: []string{"http://a:80", "http://b:80"},
+		Routes:    map[string]string{"/api": "http://a:80/v1?x=1", "/": "http://b:80"},
+		Tags:      []string{"a,b", "c"},
+		Weights:   map[string][]int{"a": {1, 2}, "b": {3}},
+	}
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+
+	m, err := Marshal("app", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["APP_TAGS"] != `"a,b",c` || m["APP_ROUTES"] != "/=http://b:80;/api=http://a:80/v1?x=1" {
+		t.Errorf("unexpected export %v", m)
+	}
+	var s2 separatorSpec
+	if err := ProcessWith(m, "app", &s2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s2, expected) {
+		t.Errorf("expected %+v, got %+v", expected, s2)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{usage_type .}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	expectedTypes := []string{
+		"Semicolon-separated list of String",
+		"Semicolon-separated list of String=String pairs",
+		"Comma-separated list of String",
+		"Space-separated list of String:Comma-separated list of Integer pairs",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); !reflect.DeepEqual(got, expectedTypes) {
+		t.Errorf("expected %q, got %q", expectedTypes, got)
+	}
+}
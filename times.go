@@ -0,0 +1,112 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// unixLayout is the layout of times given as a number of seconds since the
+// Unix epoch
+const unixLayout = "unix"
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	timeType        = reflect.TypeOf(time.Time{})
+	locationPtrType = reflect.TypeOf((*time.Location)(nil))
+)
+
+// dayUnits matches the days and weeks of a duration
+var dayUnits = regexp.MustCompile(`[0-9.]+[dw]`)
+
+// parseDuration parses a duration as time.ParseDuration does, also accepting
+// days and weeks, such as 7d or 1w2d12h. A day is always 24 hours.
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err == nil {
+		return d, nil
+	}
+	hours := dayUnits.ReplaceAllStringFunc(s, func(m string) string {
+		n, err := strconv.ParseFloat(m[:len(m)-1], 64)
+		if err != nil {
+			return m
+		}
+		if m[len(m)-1] == 'w' {
+			n *= 7
+		}
+		return strconv.FormatFloat(n*24, 'f', -1, 64) + "h"
+	})
+	if hours == s {
+		return 0, err
+	}
+	if d, herr := time.ParseDuration(hours); herr == nil {
+		return d, nil
+	}
+	// the error quotes the value as given
+	return 0, err
+}
+
+// layout returns the layout set by the `layout` tag of a time.Time field, or
+// an empty string
+func (v varInfo) layout() string {
+	typ := v.Field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ != timeType {
+		return ""
+	}
+	return v.Tags.Get("layout")
+}
+
+// assignTime parses value with layout into a time.Time field, or a pointer to
+// one
+func assignTime(value string, field reflect.Value, layout string) error {
+	t, err := parseTime(value, layout)
+	if err != nil {
+		return err
+	}
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(timeType))
+		field = field.Elem()
+	}
+	field.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// parseTime parses a time with layout, or as a number of seconds since the
+// Unix epoch for the unix layout
+func parseTime(value, layout string) (time.Time, error) {
+	if layout != unixLayout {
+		return time.Parse(layout, value)
+	}
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("invalid Unix time: " + strconv.Quote(value))
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+// formatTime is the reverse of parseTime. Times are truncated to the second
+// for the unix layout.
+func formatTime(t time.Time, layout string) string {
+	if layout == unixLayout {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	return t.Format(layout)
+}
+
+// timeDescription describes the type of a time.Time field with a layout, for
+// usage output
+func timeDescription(layout string) string {
+	if layout == unixLayout {
+		return "Unix time in seconds"
+	}
+	return "Time formatted as " + layout
+}
@@ -0,0 +1,153 @@
+package envconfig
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"90s", 90 * time.Second},
+		{"7d", 7 * 24 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"1w2d12h", 9*24*time.Hour + 12*time.Hour},
+		{"-1d", -24 * time.Hour},
+	}
+	for _, test := range tests {
+		got, err := parseDuration(test.value)
+		if err != nil {
+			t.Errorf("%q: %v", test.value, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.value, test.expected, got)
+		}
+	}
+
+	for _, value := range []string{"", "d", "7x", "1.2.3d"} {
+		if _, err := parseDuration(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+type timeSpec struct {
+	Retention time.Duration `default:"30d" max:"1w"`
+	Since     time.Time     `layout:"2006-01-02"`
+	Until     *time.Time    `layout:"2006-01-02"`
+	Created   time.Time     `layout:"unix"`
+	Updated   time.Time
+	Zone      *time.Location `default:"UTC"`
+}
+
+func TestTimes(t *testing.T) {
+	t.Parallel()
+	l := MapLookuper{
+		"APP_RETENTION": "2d",
+		"APP_SINCE":     "2021-03-04",
+		"APP_CREATED":   "1600000000",
+		"APP_UPDATED":   "2021-03-04T05:06:07Z",
+		"APP_ZONE":      "Europe/Paris",
+	}
+	var s timeSpec
+	if err := ProcessWith(l, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Retention != 48*time.Hour {
+		t.Errorf("expected Retention to be 48h, got %v", s.Retention)
+	}
+	if expected := time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC); !s.Since.Equal(expected) {
+		t.Errorf("expected Since to be %v, got %v", expected, s.Since)
+	}
+	if s.Until == nil || !s.Until.IsZero() {
+		t.Errorf("expected Until to be zero, got %v", s.Until)
+	}
+	if s.Created.Unix() != 1600000000 {
+		t.Errorf("expected Created to be 1600000000, got %v", s.Created.Unix())
+	}
+	if expected := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC); !s.Updated.Equal(expected) {
+		t.Errorf("expected Updated to be %v, got %v", expected, s.Updated)
+	}
+	if s.Zone == nil || s.Zone.String() != "Europe/Paris" {
+		t.Errorf("expected Zone to be Europe/Paris, got %v", s.Zone)
+	}
+
+	m, err := Marshal("app", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := MapLookuper{
+		"APP_RETENTION": "48h0m0s",
+		"APP_SINCE":     "2021-03-04",
+		"APP_UNTIL":     "0001-01-01",
+		"APP_CREATED":   "1600000000",
+		"APP_UPDATED":   "2021-03-04T05:06:07Z",
+		"APP_ZONE":      "Europe/Paris",
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %v, got %v", expected, m)
+	}
+
+	l["APP_UNTIL"] = "2021-04-05"
+	var s2 timeSpec
+	if err := ProcessWith(l, "app", &s2); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := Diff("app", &s, &s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "Until" || changes[0].New != "2021-04-05" {
+		t.Errorf("unexpected changes %v", changes)
+	}
+}
+
+func TestTimesErrors(t *testing.T) {
+	t.Parallel()
+	tests := []MapLookuper{
+		{"APP_RETENTION": "8d"},
+		{"APP_SINCE": "04/03/2021"},
+		{"APP_CREATED": "soon"},
+		{"APP_ZONE": "Mars/Olympus_Mons"},
+	}
+	for _, l := range tests {
+		var s timeSpec
+		if err := ProcessWith(l, "app", &s); err == nil {
+			t.Errorf("%v: expected an error", l)
+		}
+	}
+}
+
+func TestTimesUsage(t *testing.T) {
+	t.Parallel()
+	var s timeSpec
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{usage_type .}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"Duration (such as 90s, 1h30m or 7d)",
+		"Time formatted as 2006-01-02",
+		"Time formatted as 2006-01-02",
+		"Unix time in seconds",
+		"Time",
+		"Time zone",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	var bad struct {
+		Port int `layout:"unix"`
+	}
+	if issues := Lint("app", &bad); len(issues) != 1 || issues[0].Kind != IssueInvalidTag {
+		t.Errorf("expected an invalid layout, got %v", issues)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package toml loads TOML documents as an envconfig source.
+package toml
+
+import (
+	"io"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/objenious/envconfig"
+)
+
+// Parse reads a TOML document from r, and flattens it with envconfig.Flatten.
+func Parse(r io.Reader) (envconfig.MapLookuper, error) {
+	doc := map[string]interface{}{}
+	if _, err := toml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return envconfig.Flatten(doc)
+}
+
+// Load reads the TOML file at path, and flattens it with envconfig.Flatten.
+func Load(path string) (envconfig.MapLookuper, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
@@ -0,0 +1,43 @@
+package toml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/objenious/envconfig"
+)
+
+func TestParse(t *testing.T) {
+	doc := `
+httpport = 8088
+tags = ["x", "y"]
+since = 2020-01-02T03:04:05Z
+
+[pubsuba]
+topic = "topicA"
+max_extension = "11h"
+
+[[servers]]
+host = "a"
+
+[[servers]]
+host = "b"
+`
+	m, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := envconfig.MapLookuper{
+		"HTTPPORT":              "8088",
+		"TAGS":                  "x,y",
+		"SINCE":                 "2020-01-02T03:04:05Z",
+		"PUBSUBA_TOPIC":         "topicA",
+		"PUBSUBA_MAX_EXTENSION": "11h",
+		"SERVERS_0_HOST":        "a",
+		"SERVERS_1_HOST":        "b",
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %v, got %v", expected, m)
+	}
+}
@@ -20,19 +20,30 @@ const (
 	// DefaultListFormat constant to use to display usage in a list format
 	DefaultListFormat = `This application is configured via the environment. The following environment
 variables can be used:
+
+Each value can also be read from a file, by setting the variable name suffixed
+with _FILE to the path of that file. A variable takes precedence over its _FILE
+variant, which takes precedence over alternative names, then over the default.
 {{range .}}
 {{usage_key .}}
   [description] {{usage_description .}}
   [type]        {{usage_type .}}
   [default]     {{usage_default .}}
-  [required]    {{usage_required .}}{{end}}
+  [required]    {{usage_required .}}{{with usage_constraints .}}
+  [constraints] {{.}}{{end}}{{with usage_aliases .}}
+  [aliases]     {{.}}{{end}}{{with usage_deprecated .}}
+  [deprecated]  {{.}}{{end}}{{end}}
 `
 	// DefaultTableFormat constant to use to display usage in a tabular format
 	DefaultTableFormat = `This application is configured via the environment. The following environment
 variables can be used:
 
-KEY	TYPE	DEFAULT	REQUIRED	DESCRIPTION
-{{range .}}{{usage_key .}}	{{usage_type .}}	{{usage_default .}}	{{usage_required .}}	{{usage_description .}}
+Each value can also be read from a file, by setting the variable name suffixed
+with _FILE to the path of that file. A variable takes precedence over its _FILE
+variant, which takes precedence over alternative names, then over the default.
+
+KEY	TYPE	DEFAULT	REQUIRED{{if usage_any . "constraints"}}	CONSTRAINTS{{end}}{{if usage_any . "aliases"}}	ALIASES{{end}}{{if usage_any . "deprecated"}}	DEPRECATED{{end}}	DESCRIPTION
+{{range .}}{{usage_key .}}	{{usage_type .}}	{{usage_default .}}	{{usage_required .}}{{if usage_any $ "constraints"}}	{{usage_constraints .}}{{end}}{{if usage_any $ "aliases"}}	{{usage_aliases .}}{{end}}{{if usage_any $ "deprecated"}}	{{usage_deprecated .}}{{end}}	{{usage_description .}}
 {{end}}`
 )
 
@@ -55,18 +66,23 @@ func implementsInterface(t reflect.Type) bool {
 }
 
 // toTypeDescription converts Go types into a human readable description
-func toTypeDescription(t reflect.Type) string {
+func toTypeDescription(t reflect.Type, seps separators) string {
 	switch t.Kind() {
 	case reflect.Array, reflect.Slice:
-		return fmt.Sprintf("Comma-separated list of %s", toTypeDescription(t.Elem()))
+		return fmt.Sprintf("%s list of %s", seps.describe(), toTypeDescription(t.Elem(), defaultSeparators))
 	case reflect.Map:
 		return fmt.Sprintf(
-			"Comma-separated list of %s:%s pairs",
-			toTypeDescription(t.Key()),
-			toTypeDescription(t.Elem()),
+			"%s list of %s%s%s pairs",
+			seps.describe(),
+			toTypeDescription(t.Key(), defaultSeparators),
+			seps.kv,
+			toTypeDescription(t.Elem(), defaultSeparators),
 		)
 	case reflect.Ptr:
-		return toTypeDescription(t.Elem())
+		if t == locationPtrType {
+			return "Time zone"
+		}
+		return toTypeDescription(t.Elem(), seps)
 	case reflect.Struct:
 		if implementsInterface(t) && t.Name() != "" {
 			return t.Name()
@@ -85,6 +101,9 @@ func toTypeDescription(t reflect.Type) string {
 		}
 		return "True or False"
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == durationType {
+			return "Duration (such as 90s, 1h30m or 7d)"
+		}
 		name := t.Name()
 		if name != "" && !strings.HasPrefix(name, "int") {
 			return name
@@ -107,25 +126,59 @@ func toTypeDescription(t reflect.Type) string {
 }
 
 // Usage writes usage information to stderr using the default header and table format
-func Usage(prefix string, spec interface{}) error {
+func Usage(prefix string, spec interface{}, opts ...Option) error {
 	// The default is to output the usage information as a table
 	// Create tabwriter instance to support table output
 	tabs := tabwriter.NewWriter(os.Stdout, 1, 0, 4, ' ', 0)
 
-	err := Usagef(prefix, spec, tabs, DefaultTableFormat)
+	err := Usagef(prefix, spec, tabs, DefaultTableFormat, opts...)
 	tabs.Flush()
 	return err
 }
 
 // Usagef writes usage information to the specified io.Writer using the specifed template specification
-func Usagef(prefix string, spec interface{}, out io.Writer, format string) error {
+func Usagef(prefix string, spec interface{}, out io.Writer, format string, opts ...Option) error {
 
 	// Specify the default usage template functions
-	functions := template.FuncMap{
+	var functions template.FuncMap
+	functions = template.FuncMap{
 		"usage_key":         func(v varInfo) string { return v.Key },
 		"usage_description": func(v varInfo) string { return v.Tags.Get("desc") },
-		"usage_type":        func(v varInfo) string { return toTypeDescription(v.Field.Type()) },
-		"usage_default":     func(v varInfo) string { return v.Tags.Get("default") },
+		"usage_type": func(v varInfo) string {
+			typ := toTypeDescription(v.Field.Type(), v.separators())
+			if isJSON(v.Tags) {
+				typ = jsonDescription(v.Field.Type())
+			} else if layout := v.layout(); layout != "" {
+				typ = timeDescription(layout)
+			}
+			if isTrue(v.Tags.Get("file")) {
+				return "Path to file holding " + typ
+			}
+			return typ
+		},
+		"usage_default":     func(v varInfo) string { return redact(v.secret(), v.Tags.Get("default")) },
+		"usage_constraints": func(v varInfo) string { return v.constraints() },
+		"usage_aliases":     func(v varInfo) string { return strings.Join(v.Aliases, ", ") },
+		"usage_deprecated": func(v varInfo) string {
+			if msg, ok := v.Tags.Lookup("deprecated"); ok && msg == "" {
+				return "true"
+			}
+			return v.Tags.Get("deprecated")
+		},
+		// usage_any reports whether the usage_<name> function returns a
+		// value for any of the fields, to only render the sections in use
+		"usage_any": func(infos []varInfo, name string) (bool, error) {
+			f, ok := functions["usage_"+name].(func(varInfo) string)
+			if !ok {
+				return false, fmt.Errorf("usage_any: unknown usage function %q", name)
+			}
+			for _, v := range infos {
+				if f(v) != "" {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
 		"usage_required": func(v varInfo) (string, error) {
 			req := v.Tags.Get("required")
 			if req != "" {
@@ -137,6 +190,9 @@ func Usagef(prefix string, spec interface{}, out io.Writer, format string) error
 					req = "true"
 				}
 			}
+			if req != "true" && v.requirement() != "" {
+				req = v.requirement()
+			}
 			return req, nil
 		},
 	}
@@ -146,13 +202,15 @@ func Usagef(prefix string, spec interface{}, out io.Writer, format string) error
 		return err
 	}
 
-	return Usaget(prefix, spec, out, tmpl)
+	return Usaget(prefix, spec, out, tmpl, opts...)
 }
 
 // Usaget writes usage information to the specified io.Writer using the specified template
-func Usaget(prefix string, spec interface{}, out io.Writer, tmpl *template.Template) error {
+func Usaget(prefix string, spec interface{}, out io.Writer, tmpl *template.Template, opts ...Option) error {
 	// gather first
-	infos, err := gatherInfo(prefix, spec)
+	o := newOptions(opts)
+	o.usage = true
+	infos, err := gatherInfo(prefix, spec, o)
 	if err != nil {
 		return err
 	}
@@ -117,6 +117,32 @@ func TestUsageList(t *testing.T) {
 	compareUsage(testUsageListResult, buf.String(), t)
 }
 
+func TestUsageOptionalSections(t *testing.T) {
+	var s struct {
+		Host string
+		Port int `min:"1"`
+	}
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, DefaultListFormat); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "[constraints]"); n != 1 || !strings.Contains(buf.String(), "  [constraints] min 1\n") {
+		t.Errorf("expected a single [constraints] line in\n%s", buf)
+	}
+
+	buf.Reset()
+	if err := Usagef("app", &s, buf, DefaultTableFormat); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\tREQUIRED\tCONSTRAINTS\t") {
+		t.Errorf("expected a CONSTRAINTS column in\n%s", buf)
+	}
+
+	if err := Usagef("app", &s, buf, `{{usage_any . "unknown"}}`); err == nil {
+		t.Error("expected an error for an unknown usage function")
+	}
+}
+
 func TestUsageCustomFormat(t *testing.T) {
 	var s Specification
 	os.Clearenv()
@@ -0,0 +1,221 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by specifications, and nested structs, that check
+// their own values once populated, such as rules involving several fields.
+type Validator interface {
+	Validate() error
+}
+
+// validateStructs calls the Validate method of the structs implementing
+// Validator, skipping those holding one of the failed fields.
+func validateStructs(structs []structInfo, failed []string) Errors {
+	var errs Errors
+	for _, s := range structs {
+		if containsAny(s.Path, failed) {
+			continue
+		}
+		var v Validator
+		interfaceFrom(s.Value, func(i interface{}, ok *bool) { v, *ok = i.(Validator) })
+		if v == nil {
+			continue
+		}
+		if err := v.Validate(); err != nil {
+			errs = append(errs, &StructError{Path: s.Path, Prefix: s.Prefix, Err: err})
+		}
+	}
+	return errs
+}
+
+// containsAny reports whether one of the field paths is within the struct path
+func containsAny(path string, fields []string) bool {
+	for _, f := range fields {
+		if path == "" || strings.HasPrefix(f, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateField checks the value of a field against its validation tags:
+// `notempty:"true"`, `min` and `max` (bounds for numbers and durations,
+// lengths for strings, slices and maps), `oneof` (allowed values separated
+// by |) and `pattern` (a regular expression). It is only called for fields
+// that were assigned a value or a default.
+func validateField(info varInfo) error {
+	field := info.Field
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			if isTrue(info.Tags.Get("notempty")) {
+				return info.validationError("notempty", errors.New("must not be empty"))
+			}
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	if isTrue(info.Tags.Get("notempty")) && isEmpty(field) {
+		return info.validationError("notempty", errors.New("must not be empty"))
+	}
+	if min, ok := info.Tags.Lookup("min"); ok {
+		c, err := compareBound(field, min)
+		if err != nil {
+			return info.validationError("min", err)
+		}
+		if c < 0 {
+			return info.validationError("min", fmt.Errorf("must be at least %s", min))
+		}
+	}
+	if max, ok := info.Tags.Lookup("max"); ok {
+		c, err := compareBound(field, max)
+		if err != nil {
+			return info.validationError("max", err)
+		}
+		if c > 0 {
+			return info.validationError("max", fmt.Errorf("must be at most %s", max))
+		}
+	}
+	if oneof, ok := info.Tags.Lookup("oneof"); ok {
+		value, allowed := renderValue(field, info.separators()), strings.Split(oneof, "|")
+		found := false
+		for _, a := range allowed {
+			if value == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return info.validationError("oneof", fmt.Errorf("must be one of %s", strings.Join(allowed, ", ")))
+		}
+	}
+	if pattern, ok := info.Tags.Lookup("pattern"); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return info.validationError("pattern", fmt.Errorf("cannot be checked: %v", err))
+		}
+		if !re.MatchString(renderValue(field, info.separators())) {
+			return info.validationError("pattern", fmt.Errorf("must match %s", pattern))
+		}
+	}
+	return nil
+}
+
+// validationError returns a ValidationError for the current value of the field
+func (v varInfo) validationError(rule string, err error) *ValidationError {
+	return &ValidationError{
+		KeyName:   v.Key,
+		FieldName: v.Name,
+		Rule:      rule,
+		Value:     renderValue(v.Field, v.separators()),
+		Err:       err,
+		secret:    v.secret(),
+	}
+}
+
+// isEmpty reports whether a value is empty: a zero length for strings,
+// slices and maps, the zero value otherwise.
+func isEmpty(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return field.Len() == 0
+	}
+	return field.IsZero()
+}
+
+// compareBound compares a value, or its length, to a bound given as a string.
+func compareBound(field reflect.Value, bound string) (int, error) {
+	typ := field.Type()
+	switch typ.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		b, err := strconv.Atoi(bound)
+		if err != nil {
+			return 0, fmt.Errorf("cannot be checked: invalid length bound %q", bound)
+		}
+		return compareInts(int64(field.Len()), int64(b)), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var (
+			b   int64
+			err error
+		)
+		if typ.Kind() == reflect.Int64 && typ.PkgPath() == "time" && typ.Name() == "Duration" {
+			var d time.Duration
+			d, err = parseDuration(bound)
+			b = int64(d)
+		} else {
+			b, err = strconv.ParseInt(bound, 0, 64)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("cannot be checked: invalid bound %q", bound)
+		}
+		return compareInts(field.Int(), b), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b, err := strconv.ParseUint(bound, 0, 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot be checked: invalid bound %q", bound)
+		}
+		switch v := field.Uint(); {
+		case v < b:
+			return -1, nil
+		case v > b:
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.Float32, reflect.Float64:
+		b, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot be checked: invalid bound %q", bound)
+		}
+		switch v := field.Float(); {
+		case v < b:
+			return -1, nil
+		case v > b:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("cannot be checked: bounds are not supported for type %s", typ)
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// constraints describes the validation tags of a field, for usage output
+func (v varInfo) constraints() string {
+	var c []string
+	if isTrue(v.Tags.Get("notempty")) {
+		c = append(c, "not empty")
+	}
+	if min, ok := v.Tags.Lookup("min"); ok {
+		c = append(c, "min "+min)
+	}
+	if max, ok := v.Tags.Lookup("max"); ok {
+		c = append(c, "max "+max)
+	}
+	if oneof, ok := v.Tags.Lookup("oneof"); ok {
+		c = append(c, "one of "+oneof)
+	}
+	if pattern, ok := v.Tags.Lookup("pattern"); ok {
+		c = append(c, "matching "+pattern)
+	}
+	return strings.Join(c, ", ")
+}
@@ -0,0 +1,199 @@
+package envconfig
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type validatedSpec struct {
+	Port     int           `default:"8080" min:"1" max:"65535"`
+	Level    string        `default:"info" oneof:"debug|info|warn"`
+	URL      string        `pattern:"^https?://"`
+	Timeout  time.Duration `default:"1s" min:"100ms" max:"1m"`
+	Hosts    []string      `max:"2"`
+	Ratio    float64       `max:"1.5"`
+	Name     string        `notempty:"true" default:"app"`
+	Password string        `secret:"true" min:"8"`
+}
+
+func TestValidation(t *testing.T) {
+	t.Parallel()
+	var s validatedSpec
+	if err := ProcessWith(MapLookuper{"APP_URL": "https://example.com"}, "app", &s); err != nil {
+		t.Fatal(err)
+	}
+
+	l := MapLookuper{
+		"APP_PORT":     "70000",
+		"APP_LEVEL":    "trace",
+		"APP_URL":      "ftp://example.com",
+		"APP_TIMEOUT":  "10ms",
+		"APP_HOSTS":    "a,b,c",
+		"APP_RATIO":    "2",
+		"APP_NAME":     "",
+		"APP_PASSWORD": "short",
+	}
+	err := ProcessWith(l, "app", &s)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %T %v", err, err)
+	}
+	expected := []string{
+		"envconfig.Process: validating APP_PORT for Port: '70000' must be at most 65535",
+		"envconfig.Process: validating APP_LEVEL for Level: 'trace' must be one of debug, info, warn",
+		"envconfig.Process: validating APP_URL for URL: 'ftp://example.com' must match ^https?://",
+		"envconfig.Process: validating APP_TIMEOUT for Timeout: '10ms' must be at least 100ms",
+		"envconfig.Process: validating APP_HOSTS for Hosts: 'a,b,c' must be at most 2",
+		"envconfig.Process: validating APP_RATIO for Ratio: '2' must be at most 1.5",
+		"envconfig.Process: validating APP_NAME for Name: '' must not be empty",
+		"envconfig.Process: validating APP_PASSWORD for Password: '******' must be at least 8",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if _, ok := err.(*ValidationError); !ok {
+			t.Errorf("expected ValidationError, got %T", err)
+		}
+		if err.Error() != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], err)
+		}
+	}
+}
+
+func TestValidationInvalidTag(t *testing.T) {
+	t.Parallel()
+	var s struct {
+		Enabled bool `min:"1"`
+		Port    int  `max:"lots"`
+	}
+	err := ProcessWith(MapLookuper{"APP_ENABLED": "false", "APP_PORT": "1"}, "app", &s)
+	var v *ValidationError
+	if !errors.As(err, &v) || v.Rule != "min" {
+		t.Fatalf("expected ValidationError for min, got %v", err)
+	}
+	if experr := "envconfig.Process: validating APP_ENABLED for Enabled: 'false' cannot be checked: bounds are not supported for type bool"; v.Error() != experr {
+		t.Errorf("expected %s, got %s", experr, v)
+	}
+}
+
+func TestUsageConstraints(t *testing.T) {
+	t.Parallel()
+	var s validatedSpec
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{usage_key .}}={{usage_constraints .}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"APP_PORT=min 1, max 65535",
+		"APP_LEVEL=one of debug|info|warn",
+		"APP_URL=matching ^https?://",
+		"APP_NAME=not empty",
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected %q in %q", line, buf)
+		}
+	}
+}
+
+type tlsConfig struct {
+	Cert string
+	Key  string
+}
+
+func (c tlsConfig) Validate() error {
+	if c.Cert != "" && c.Key == "" {
+		return errors.New("a certificate requires a key")
+	}
+	return nil
+}
+
+type rangeConfig struct {
+	Min int
+	Max int
+}
+
+func (c *rangeConfig) Validate() error {
+	if c.Min > c.Max {
+		return errors.New("min must not exceed max")
+	}
+	return nil
+}
+
+type validatorSpec struct {
+	TLS    tlsConfig
+	Range  *rangeConfig
+	Broken rangeConfig
+	Name   string
+}
+
+func (s *validatorSpec) Validate() error {
+	if s.Name == "" {
+		return errors.New("missing name")
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	t.Parallel()
+	var s validatorSpec
+	l := MapLookuper{
+		"APP_NAME":       "app",
+		"APP_RANGE_MAX":  "10",
+		"APP_BROKEN_MIN": "2",
+		"APP_BROKEN_MAX": "1",
+	}
+	err := ProcessWith(l, "app", &s)
+	var v *StructError
+	if !errors.As(err, &v) {
+		t.Fatalf("expected StructError, got %T %v", err, err)
+	}
+	if v.Path != "Broken" || v.Prefix != "APP_BROKEN" {
+		t.Errorf("expected Broken (APP_BROKEN), got %s (%s)", v.Path, v.Prefix)
+	}
+	if experr := "envconfig.Process: validating Broken (APP_BROKEN): min must not exceed max"; err.Error() != experr {
+		t.Errorf("expected %s, got %s", experr, err)
+	}
+
+	// failures are gathered, nested structs first
+	s = validatorSpec{}
+	l = MapLookuper{
+		"APP_TLS_CERT":  "cert.pem",
+		"APP_RANGE_MIN": "2",
+	}
+	err = ProcessWith(l, "app", &s)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %T %v", err, err)
+	}
+	expected := []string{
+		"envconfig.Process: validating TLS (APP_TLS): a certificate requires a key",
+		"envconfig.Process: validating Range (APP_RANGE): min must not exceed max",
+		"envconfig.Process: validating specification (APP): missing name",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], err)
+		}
+	}
+}
+
+func TestValidatorSkippedOnFieldErrors(t *testing.T) {
+	t.Parallel()
+	var s validatorSpec
+	l := MapLookuper{
+		"APP_NAME":       "app",
+		"APP_BROKEN_MIN": "two",
+		"APP_BROKEN_MAX": "1",
+	}
+	err := ProcessWith(l, "app", &s)
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("expected a single ParseError, got %T %v", err, err)
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A Source returns the Lookuper to process a specification with. It is called
+// on each reload of a Watcher, so that files can be read again.
+type Source func() (Lookuper, error)
+
+// A ChangeFunc is called by a Watcher when a reload changes the specification,
+// with the previous and the new specifications, and the keys whose values
+// changed.
+type ChangeFunc func(prev, next interface{}, changed []string)
+
+// A Watcher keeps a specification up to date with its source, for services
+// picking up configuration changes without restarting.
+//
+// Each reload processes the source into a new zero value of the specification,
+// validating it as ProcessWith does. Only when this succeeds, and some values
+// changed, is the new specification swapped in and the ChangeFunc callbacks
+// called. Specifications returned by Current are never modified afterwards,
+// and can be read without locking.
+type Watcher struct {
+	source Source
+	prefix string
+	typ    reflect.Type
+	opts   []Option
+
+	current atomic.Value
+
+	// mu serializes reloads and guards the callbacks
+	mu       sync.Mutex
+	onChange []ChangeFunc
+	onError  func(error)
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewWatcher processes spec, which must be a pointer to a struct, with the
+// Lookuper returned by source, or with the environment if source is nil.
+// spec is the first specification returned by Current.
+func NewWatcher(source Source, prefix string, spec interface{}, opts ...Option) (*Watcher, error) {
+	s := reflect.ValueOf(spec)
+	if s.Kind() != reflect.Ptr || s.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidSpecification
+	}
+	if source == nil {
+		source = func() (Lookuper, error) { return OsLookuper(), nil }
+	}
+
+	w := &Watcher{
+		source: source,
+		prefix: prefix,
+		typ:    s.Elem().Type(),
+		opts:   opts,
+		stop:   make(chan struct{}),
+	}
+	if err := w.process(spec); err != nil {
+		return nil, err
+	}
+	w.current.Store(spec)
+	return w, nil
+}
+
+// Current returns the latest specification, a pointer to a struct of the same
+// type as the one given to NewWatcher.
+func (w *Watcher) Current() interface{} {
+	return w.current.Load()
+}
+
+// OnChange registers f to be called after each reload changing the
+// specification. Callbacks are called in order, while no other reload can
+// happen: they must not call Reload.
+func (w *Watcher) OnChange(f ChangeFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onChange = append(w.onChange, f)
+}
+
+// OnError sets the function called with the errors of the reloads triggered
+// by WatchSignals and WatchFiles. They are ignored by default.
+func (w *Watcher) OnError(f func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = f
+}
+
+// Reload processes the source again. On error, the current specification is
+// kept and the error is returned.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	spec := reflect.New(w.typ).Interface()
+	if err := w.process(spec); err != nil {
+		return err
+	}
+
+	prev := w.current.Load()
+	changed, err := changedKeys(w.prefix, prev, spec, w.opts)
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	w.current.Store(spec)
+	for _, f := range w.onChange {
+		f(prev, spec, changed)
+	}
+	return nil
+}
+
+// process populates spec from the source
+func (w *Watcher) process(spec interface{}) error {
+	l, err := w.source()
+	if err != nil {
+		return err
+	}
+	return ProcessWith(l, w.prefix, spec, w.opts...)
+}
+
+// WatchSignals reloads the specification each time one of the signals is
+// received, such as syscall.SIGHUP, until Stop is called.
+func (w *Watcher) WatchSignals(sigs ...os.Signal) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer signal.Stop(c)
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-c:
+				w.reload()
+			}
+		}
+	}()
+}
+
+// WatchFiles checks the modification time and size of the files every
+// interval, and reloads the specification when one of them changed, until
+// Stop is called.
+func (w *Watcher) WatchFiles(interval time.Duration, paths ...string) {
+	stats := statFiles(paths)
+	ticker := time.NewTicker(interval)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				current := statFiles(paths)
+				if !sameStats(current, stats) {
+					stats = current
+					w.reload()
+				}
+			}
+		}
+	}()
+}
+
+// reload reloads the specification, passing any error to the OnError function
+func (w *Watcher) reload() {
+	if err := w.Reload(); err != nil {
+		w.mu.Lock()
+		onError := w.onError
+		w.mu.Unlock()
+		if onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// Stop stops watching signals and files.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	w.wg.Wait()
+}
+
+// fileStat holds what WatchFiles compares to detect a change
+type fileStat struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func statFiles(paths []string) []fileStat {
+	stats := make([]fileStat, len(paths))
+	for i, path := range paths {
+		if fi, err := os.Stat(path); err == nil {
+			stats[i] = fileStat{modTime: fi.ModTime(), size: fi.Size(), exists: true}
+		}
+	}
+	return stats
+}
+
+func sameStats(a, b []fileStat) bool {
+	for i := range a {
+		if a[i].exists != b[i].exists || a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// changedKeys lists the keys whose values differ between two specifications
+func changedKeys(prefix string, prev, next interface{}, opts []Option) ([]string, error) {
+	changes, err := Diff(prefix, prev, next, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for i, c := range changes {
+		if i == 0 || c.Key != changes[i-1].Key {
+			keys = append(keys, c.Key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,129 @@
+package envconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type watchedSpec struct {
+	Port    int    `default:"8080" min:"1"`
+	Level   string `default:"info"`
+	Workers int    `default:"4"`
+}
+
+func TestWatcherReload(t *testing.T) {
+	t.Parallel()
+	env := MapLookuper{"APP_PORT": "80"}
+	source := func() (Lookuper, error) { return env, nil }
+
+	var s watchedSpec
+	w, err := NewWatcher(source, "app", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Current() != &s || s.Port != 80 {
+		t.Fatalf("expected the initial specification, got %+v", w.Current())
+	}
+
+	var calls int
+	var changed []string
+	w.OnChange(func(prev, next interface{}, keys []string) {
+		calls++
+		changed = keys
+		if prev.(*watchedSpec).Port != 80 || next.(*watchedSpec).Port != 81 {
+			t.Errorf("unexpected change from %+v to %+v", prev, next)
+		}
+	})
+
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no call without a change, got %d", calls)
+	}
+
+	env = MapLookuper{"APP_PORT": "81", "APP_LEVEL": "debug"}
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || !reflect.DeepEqual(changed, []string{"APP_PORT", "APP_LEVEL"}) {
+		t.Errorf("expected a call with APP_PORT and APP_LEVEL, got %d calls with %v", calls, changed)
+	}
+	if s.Port != 80 {
+		t.Errorf("expected the initial specification to be left alone, got %+v", s)
+	}
+
+	env = MapLookuper{"APP_PORT": "0"}
+	if err := w.Reload(); err == nil {
+		t.Error("expected a validation error")
+	}
+	expected := watchedSpec{Port: 81, Level: "debug", Workers: 4}
+	if current := w.Current().(*watchedSpec); *current != expected {
+		t.Errorf("expected %+v, got %+v", expected, *current)
+	}
+}
+
+func TestWatcherInvalidSpecification(t *testing.T) {
+	t.Parallel()
+	if _, err := NewWatcher(nil, "app", watchedSpec{}); err != ErrInvalidSpecification {
+		t.Errorf("expected %v, got %v", ErrInvalidSpecification, err)
+	}
+}
+
+func TestWatchFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"port": 80}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	source := func() (Lookuper, error) { return LoadJSON(path) }
+
+	var s watchedSpec
+	w, err := NewWatcher(source, "", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	changes := make(chan []string, 1)
+	errs := make(chan error, 1)
+	w.OnChange(func(prev, next interface{}, keys []string) { changes <- keys })
+	w.OnError(func(err error) { errs <- err })
+	w.WatchFiles(10*time.Millisecond, path)
+
+	if err := ioutil.WriteFile(path, []byte(`{"port": 8000, "workers": 8}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case keys := <-changes:
+		if !reflect.DeepEqual(keys, []string{"PORT", "WORKERS"}) {
+			t.Errorf("expected PORT and WORKERS, got %v", keys)
+		}
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload")
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"port": "none"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs:
+		if _, ok := err.(*ParseError); !ok {
+			t.Errorf("expected a ParseError, got %T %v", err, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload")
+	}
+	if current := w.Current().(*watchedSpec); current.Port != 8000 {
+		t.Errorf("expected 8000, got %d", current.Port)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2013 Kelsey Hightower. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package yaml loads YAML documents as an envconfig source.
+package yaml
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/objenious/envconfig"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Parse reads a YAML document from r, and flattens it with envconfig.Flatten.
+func Parse(r io.Reader) (envconfig.MapLookuper, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return envconfig.Flatten(doc)
+}
+
+// Load reads the YAML file at path, and flattens it with envconfig.Flatten.
+func Load(path string) (envconfig.MapLookuper, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
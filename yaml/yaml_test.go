@@ -0,0 +1,39 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/objenious/envconfig"
+)
+
+func TestParse(t *testing.T) {
+	doc := `
+pubsuba:
+  topic: topicA
+  max_extension: 11h
+httpport: 8088
+servers:
+  - host: a
+    port: 1
+  - host: b
+tags: [a, b]
+`
+	m, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := envconfig.MapLookuper{
+		"PUBSUBA_TOPIC":         "topicA",
+		"PUBSUBA_MAX_EXTENSION": "11h",
+		"HTTPPORT":              "8088",
+		"SERVERS_0_HOST":        "a",
+		"SERVERS_0_PORT":        "1",
+		"SERVERS_1_HOST":        "b",
+		"TAGS":                  "a,b",
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %v, got %v", expected, m)
+	}
+}